@@ -113,6 +113,34 @@ headers
 -------
 {{ "Config.Headers" | doc }}
 
+data-format
+-----------
+{{ "Config.DataFormat" | doc }}
+
+csv
+---
+{{ "Config.CSV" | doc }}
+
+skip-rows
+---------
+{{ "Config.SkipRows" | doc }}
+
+data-loop
+---------
+{{ "Config.DataLoop" | doc }}
+
+data-order
+----------
+{{ "Config.DataOrder" | doc }}
+
+data-buffer
+-----------
+{{ "Config.DataBuffer" | doc }}
+
+generate
+--------
+{{ "Config.Generate" | doc }}
+
 log-data
 --------
 {{ "Config.LogData" | doc }}
@@ -133,6 +161,82 @@ payload-variants
 ----------------
 {{ "Config.PayloadVariants" | doc }}
 
+rate-schedule
+-------------
+{{ "Config.RateSchedule" | doc }}
+
+correct-latency
+---------------
+{{ "Config.CorrectLatency" | doc }}
+
+arrival
+-------
+{{ "Config.Arrival" | doc }}
+
+closed-loop
+-----------
+{{ "Config.ClosedLoop" | doc }}
+
+search
+------
+{{ "Config.Search" | doc }}
+
+duration
+--------
+{{ "Config.Duration" | doc }}
+
+max-requests
+------------
+{{ "Config.MaxRequests" | doc }}
+
+max-failures
+------------
+{{ "Config.MaxFailures" | doc }}
+
+max-fail-fraction
+-----------------
+{{ "Config.MaxFailFraction" | doc }}
+
+retry
+-----
+{{ "Config.Retry" | doc }}
+
+circuit-breaker
+---------------
+{{ "Config.CircuitBreaker" | doc }}
+
+percentiles
+-----------
+{{ "Config.Percentiles" | doc }}
+
+report-file
+-----------
+{{ "Config.ReportFile" | doc }}
+
+output-format
+-------------
+{{ "Config.OutputFormat" | doc }}
+
+metrics-addr
+------------
+{{ "Config.MetricsAddr" | doc }}
+
+control-addr
+------------
+{{ "Config.ControlAddr" | doc }}
+
+timeseries-file
+---------------
+{{ "Config.TimeseriesFile" | doc }}
+
+timeseries-interval
+-------------------
+{{ "Config.TimeseriesInterval" | doc }}
+
+html-report
+-----------
+{{ "Config.HTMLReport" | doc }}
+
 Control by code
 ===============
 The blaster package may be used to start blast from code without using the command. Here's a some 
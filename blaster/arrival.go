@@ -0,0 +1,22 @@
+package blaster
+
+import (
+	"math/rand"
+)
+
+// arrivals maps the arrival option to a function returning the interval until the next tick as a
+// multiple of the mean interval. Each distribution has a mean of 1, so the actual rate converges on
+// the desired rate.
+var arrivals = map[string]func() float64{
+	"constant": func() float64 {
+		return 1
+	},
+	"poisson": func() float64 {
+		// exponentially distributed intervals give a poisson arrival process
+		return rand.ExpFloat64()
+	},
+	"uniform-jitter": func() float64 {
+		// uniformly distributed between 0.5 and 1.5 times the mean interval
+		return 0.5 + rand.Float64()
+	},
+}
@@ -2,7 +2,9 @@ package blaster
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/signal"
 	"sync"
@@ -47,13 +49,93 @@ type Blaster struct {
 	// WorkerVariants sets the worker variants. See Config.WorkerVariants for more details.
 	WorkerVariants []map[string]string
 
+	// RateSchedule sets the rate schedule. See Config.RateSchedule for more details.
+	RateSchedule []RateStage
+
+	// CorrectLatency sets the correct-latency option. See Config.CorrectLatency for more details.
+	CorrectLatency bool
+
+	// Arrival sets the arrival distribution. See Config.Arrival for more details.
+	Arrival string
+
+	// ClosedLoop sets the closed-loop mode. See Config.ClosedLoop for more details.
+	ClosedLoop bool
+
+	// Search sets the capacity search mode. See Config.Search for more details.
+	Search *Search
+
+	// Duration sets the length of the run. See Config.Duration for more details.
+	Duration time.Duration
+
+	// MaxRequests ends the run after this many requests. See Config.MaxRequests for more details.
+	MaxRequests int64
+
+	// MaxFailures ends the run after this many failures. See Config.MaxFailures for more details.
+	MaxFailures int64
+
+	// MaxFailFraction ends the run when this fraction of requests have failed. See Config.MaxFailFraction for more details.
+	MaxFailFraction float64
+
+	// Retry sets the retry policy for failed items. See Config.Retry for more details.
+	Retry *Retry
+
+	// CircuitBreaker pauses sending when the target is failing. See Config.CircuitBreaker for more details.
+	CircuitBreaker *CircuitBreaker
+
+	// Percentiles sets the latency percentiles to report. See Config.Percentiles for more details.
+	Percentiles []float64
+
+	// OutputFormat sets the format of the report. See Config.OutputFormat for more details.
+	OutputFormat string
+
+	// DataFormat sets the format of the data source: `csv` (the default) or `jsonl`. This must be set
+	// before SetData. See Config.DataFormat for more details.
+	DataFormat string
+
+	// CSV sets how csv data is read. This must be set before SetData. See Config.CSV for more details.
+	CSV *CSV
+
+	// SkipRows sets the number of lines skipped at the start of the data. This must be set before
+	// SetData. See Config.SkipRows for more details.
+	SkipRows int
+
+	// DataLoop sets the number of passes over the data, or -1 to loop until the run is stopped. See
+	// Config.DataLoop for more details.
+	DataLoop int
+
+	// DataOrder sets the order the data is sent in: `file` (the default), `shuffle` or `random`. See
+	// Config.DataOrder for more details.
+	DataOrder string
+
+	// DataBuffer sets the number of items held in memory when DataOrder is `shuffle` or `random`. See
+	// Config.DataBuffer for more details.
+	DataBuffer int
+
+	// Generate sets the generated data columns. See Config.Generate for more details.
+	Generate []Generator
+
+	// TimeseriesInterval sets the interval between rows in the time-series output. See
+	// Config.TimeseriesInterval for more details.
+	TimeseriesInterval time.Duration
+
+	// TimeseriesFormat sets the format of the time-series output: `csv` (the default) or `json` (json
+	// lines). See Config.TimeseriesFile for more details.
+	TimeseriesFormat string
+
+	// MetricsAddr sets the address of the Prometheus metrics listener. See Config.MetricsAddr for more details.
+	MetricsAddr string
+
+	// ControlAddr sets the address of the HTTP control server. See Config.ControlAddr for more details.
+	ControlAddr string
+
 	workerFunc func() Worker
 
 	viper *viper.Viper
 
-	softTimeout time.Duration
-	hardTimeout time.Duration
-	skip        map[farmhash.Uint128]struct{}
+	softTimeout  time.Duration
+	hardTimeout  time.Duration
+	timeoutMutex sync.RWMutex
+	skip         map[farmhash.Uint128]struct{}
 
 	logWriter  csvWriteFlusher
 	logCloser  io.Closer
@@ -61,6 +143,13 @@ type Blaster struct {
 	outCloser  io.Closer
 	dataReader csvReader
 	dataCloser io.Closer
+	dataMutex  sync.RWMutex // guards dataReader, which is replaced when the data is re-opened
+	reopenData func() error // re-opens the data source for the next pass, or nil if it can't be re-opened
+	dataOrder  *dataOrderDef
+
+	timeseriesWriter io.Writer
+	timeseriesCloser io.Closer
+	timeseriesRows   []TimeseriesRow // only recorded for the html report
 
 	inputReader io.Reader
 
@@ -69,7 +158,7 @@ type Blaster struct {
 	payloadRenderer renderer
 	workerRenderer  renderer
 
-	mainChannel            chan int
+	mainChannel            chan tickDef
 	errorChannel           chan error
 	workerChannel          chan workDef
 	logChannel             chan logRecord
@@ -77,10 +166,28 @@ type Blaster struct {
 	workersFinishedChannel chan struct{}
 	itemFinishedChannel    chan struct{}
 	changeRateChannel      chan float64
+	changeWorkersChannel   chan int
+	scheduleChannel        chan rateChange
 	signalChannel          chan os.Signal
 
-	mainWait   *sync.WaitGroup
-	workerWait *sync.WaitGroup
+	mainWait    *sync.WaitGroup
+	workerWait  *sync.WaitGroup
+	workerStops []chan struct{}
+	finishOnce  sync.Once
+	retryDef    *retryDef
+	generators  []generatorDef
+	retries     *retryQueue
+	breaker     *breakerDef
+	config      *Config
+	reportFile  string
+	htmlReport  string
+	startTime   time.Time
+	endTime     time.Time
+
+	metricsListener net.Listener
+	controlListener net.Listener
+	paused          int32 // accessed atomically
+	workers         int64 // current number of workers (Workers is the initial number), accessed atomically
 
 	workerTypes map[string]func() Worker
 
@@ -88,14 +195,24 @@ type Blaster struct {
 	metrics       *metricsDef
 	err           error
 	gcs           opener
+	stdin         io.Reader
+	dataStdin     bool // data is read from stdin, so it can't be used for interactive commands
 }
 
-// SetTimeout sets the timeout. See Config.Timeout for more details.
+// SetTimeout sets the timeout. See Config.Timeout for more details. This may be called during execution.
 func (b *Blaster) SetTimeout(timeout time.Duration) {
+	b.timeoutMutex.Lock()
+	defer b.timeoutMutex.Unlock()
 	b.softTimeout = timeout
 	b.hardTimeout = timeout + time.Second
 }
 
+func (b *Blaster) timeouts() (soft, hard time.Duration) {
+	b.timeoutMutex.RLock()
+	defer b.timeoutMutex.RUnlock()
+	return b.softTimeout, b.hardTimeout
+}
+
 // SetWorker sets the worker creation function. See httpworker for a simple example.
 func (b *Blaster) SetWorker(wf func() Worker) {
 	b.workerFunc = wf
@@ -141,7 +258,48 @@ func (b *Blaster) SetOutput(w io.Writer) {
 
 // ChangeRate changes the sending rate during execution.
 func (b *Blaster) ChangeRate(rate float64) {
-	b.changeRateChannel <- rate
+	select {
+	case b.changeRateChannel <- rate:
+	case <-b.dataFinishedChannel:
+		// the ticker loop has exited, so nobody will receive the change.
+	case <-b.workersFinishedChannel:
+		// notest
+	}
+}
+
+// SetPaused pauses or resumes sending during execution. While paused, ticks are skipped without reading any data.
+func (b *Blaster) SetPaused(paused bool) {
+	if paused {
+		atomic.StoreInt32(&b.paused, 1)
+		b.println("Paused.")
+	} else {
+		atomic.StoreInt32(&b.paused, 0)
+		b.println("Resumed.")
+	}
+}
+
+// Stop ends the run gracefully during execution: workers finish their current items, and the final stats are printed.
+func (b *Blaster) Stop() {
+	b.finish("stopped")
+}
+
+// ChangeWorkers changes the number of workers during execution. New workers are started with the rotated worker variants, and surplus workers finish their current item before they are stopped.
+func (b *Blaster) ChangeWorkers(count int) {
+	select {
+	case b.changeWorkersChannel <- count:
+	case <-b.dataFinishedChannel:
+		// the workers loop has exited, so nobody will receive the change.
+	case <-b.workersFinishedChannel:
+		// notest
+	}
+}
+
+// currentWorkers returns the number of workers. This may be called during execution.
+func (b *Blaster) currentWorkers() int {
+	if count := atomic.LoadInt64(&b.workers); count > 0 {
+		return int(count)
+	}
+	return b.Workers
 }
 
 // New creates a new Blaster with defaults.
@@ -157,17 +315,25 @@ func New(ctx context.Context, cancel context.CancelFunc) *Blaster {
 		dataFinishedChannel:    make(chan struct{}),
 		workersFinishedChannel: make(chan struct{}),
 		changeRateChannel:      make(chan float64, 1),
+		changeWorkersChannel:   make(chan int, 1),
+		scheduleChannel:        make(chan rateChange),
 		errorChannel:           make(chan error),
 		logChannel:             make(chan logRecord),
-		mainChannel:            make(chan int),
+		mainChannel:            make(chan tickDef),
 		workerChannel:          make(chan workDef),
 		Rate:                   10,
 		Workers:                10,
+		Percentiles:            []float64{50, 90, 95, 99, 99.9},
+		TimeseriesInterval:     time.Second,
+		DataBuffer:             10000,
 		softTimeout:            time.Second,
 		hardTimeout:            time.Second * 2,
 		WorkerVariants:         []map[string]string{{}},
 		PayloadVariants:        []map[string]string{{}},
 		gcs:                    googleCloudOpener{},
+		stdin:                  os.Stdin,
+		retries:                &retryQueue{},
+		dataOrder:              &dataOrderDef{},
 	}
 	b.metrics = newMetricsDef(b)
 
@@ -200,6 +366,9 @@ func (b *Blaster) Exit() {
 	if b.dataCloser != nil {
 		_ = b.dataCloser.Close() // ignore error
 	}
+	if b.timeseriesCloser != nil {
+		_ = b.timeseriesCloser.Close() // ignore error
+	}
 	signal.Stop(b.signalChannel)
 	b.cancel()
 }
@@ -209,6 +378,10 @@ func (b *Blaster) Command(ctx context.Context) error {
 
 	// notest
 
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		return b.compareCommand(os.Args[2:])
+	}
+
 	c, err := b.LoadConfig()
 	if err != nil {
 		return err
@@ -220,11 +393,19 @@ func (b *Blaster) Command(ctx context.Context) error {
 
 	b.SetOutput(os.Stdout)
 
-	if !b.Quiet {
+	if !b.Quiet && !b.dataStdin {
 		b.SetInput(os.Stdin)
 	}
 
-	_, err = b.Start(ctx)
+	stats, err := b.Start(ctx)
+
+	if reportErr := b.writeReportFile(stats); reportErr != nil && err == nil {
+		err = reportErr
+	}
+
+	if reportErr := b.writeHTMLReportFile(stats); reportErr != nil && err == nil {
+		err = reportErr
+	}
 
 	return err
 }
@@ -232,8 +413,8 @@ func (b *Blaster) Command(ctx context.Context) error {
 // Start starts the blast run without processing any config.
 func (b *Blaster) Start(ctx context.Context) (Stats, error) {
 
-	if b.dataReader == nil && b.Resume {
-		panic("In resume mode, data must be specified!")
+	if b.dataReader == nil && len(b.Generate) == 0 && b.Resume {
+		panic("In resume mode, data or generated columns must be specified!")
 	}
 
 	if b.logWriter == nil && b.Resume {
@@ -256,6 +437,117 @@ func (b *Blaster) Start(ctx context.Context) (Stats, error) {
 		panic("Rate must not be negative!")
 	}
 
+	if _, ok := arrivals[b.Arrival]; !ok && b.Arrival != "" {
+		panic(fmt.Sprintf("Arrival %s not found!", b.Arrival))
+	}
+
+	for _, stage := range b.RateSchedule {
+		if _, err := stage.validate(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	if b.ClosedLoop && len(b.RateSchedule) > 0 {
+		panic("Rate schedule can't be used in closed-loop mode!")
+	}
+
+	if b.Search != nil {
+		if _, err := b.Search.parse(b.Rate); err != nil {
+			panic(err.Error())
+		}
+		if b.ClosedLoop || len(b.RateSchedule) > 0 {
+			panic("Search can't be used with a rate schedule or in closed-loop mode!")
+		}
+	}
+
+	if b.Duration < 0 || b.MaxRequests < 0 || b.MaxFailures < 0 || b.MaxFailFraction < 0 {
+		panic("Stop conditions must not be negative!")
+	}
+
+	if b.MaxFailFraction > 1 {
+		panic("Max fail fraction must not be greater than 1!")
+	}
+
+	if b.Retry != nil {
+		if _, err := b.Retry.parse(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	if b.CircuitBreaker != nil {
+		if _, err := b.CircuitBreaker.parse(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	if _, err := parseGenerators(b.Generate); err != nil {
+		panic(err.Error())
+	}
+
+	if b.Resume && !resumableGenerators(b.Generate) {
+		panic("In resume mode, only sequence generated columns can be used!")
+	}
+
+	for _, p := range b.Percentiles {
+		if p <= 0 || p > 100 {
+			panic("Percentiles must be between 0 and 100!")
+		}
+	}
+
+	switch b.OutputFormat {
+	case "", "text", "json":
+	default:
+		panic(fmt.Sprintf("Output format %s not found!", b.OutputFormat))
+	}
+
+	switch b.DataFormat {
+	case "", "csv", "jsonl":
+	default:
+		panic(fmt.Sprintf("Data format %s not found!", b.DataFormat))
+	}
+
+	if b.CSV != nil {
+		if _, _, err := b.CSV.parse(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	if b.SkipRows < 0 {
+		panic("Skip rows must not be negative!")
+	}
+
+	if b.DataLoop < -1 {
+		panic("Data loop must be -1 or more!")
+	}
+
+	if b.DataLoop != 0 && b.Resume {
+		panic("Data loop can't be used in resume mode!")
+	}
+
+	if b.DataLoop != 0 && b.dataStdin && b.DataOrder != "random" {
+		panic("Data loop can't be used with stdin data unless data order is random!")
+	}
+
+	switch b.DataOrder {
+	case "", "file", "shuffle", "random":
+	default:
+		panic(fmt.Sprintf("Data order %s not found!", b.DataOrder))
+	}
+
+	if (b.DataOrder == "shuffle" || b.DataOrder == "random") && b.DataBuffer < 1 {
+		panic("Data buffer must be positive!")
+	}
+
+	if (b.timeseriesWriter != nil || b.htmlReport != "") && b.TimeseriesInterval <= 0 {
+		panic("TimeseriesInterval must be positive!")
+	}
+
+	switch b.TimeseriesFormat {
+	case "", "csv", "json":
+	default:
+		panic(fmt.Sprintf("Timeseries format %s not found!", b.TimeseriesFormat))
+	}
+
 	err := b.start(ctx)
 
 	return b.Stats(), err
@@ -268,14 +560,47 @@ func (b *Blaster) Stats() Stats {
 
 func (b *Blaster) start(ctx context.Context) error {
 
-	b.metrics.addSegment(b.Rate)
+	b.startTime = time.Now()
+	defer func() { b.endTime = time.Now() }()
+
+	if b.Retry != nil {
+		def, _ := b.Retry.parse() // already validated in Start
+		b.retryDef = &def
+	}
+
+	if b.CircuitBreaker != nil {
+		breaker, _ := b.CircuitBreaker.parse() // already validated in Start
+		// Stats may be called at any time, and reads the breaker under the metrics lock.
+		b.metrics.sync.Lock()
+		b.breaker = breaker
+		b.metrics.sync.Unlock()
+	}
+
+	b.generators, _ = parseGenerators(b.Generate) // already validated in Start
+
+	if err := b.listen(); err != nil {
+		return err
+	}
+	b.startMetricsServer(ctx)
+	b.startControlServer(ctx)
+
+	rate, desired := b.initialRate()
+	if rate != b.Rate {
+		// only write the rate when it changes, because callers may read it during the run.
+		b.Rate = rate
+	}
+	b.metrics.addSegment(desired)
 
 	b.startTickerLoop(ctx)
+	b.startScheduleLoop(ctx)
+	b.startSearchLoop(ctx)
+	b.startStopLoop(ctx)
 	b.startMainLoop(ctx)
 	b.startErrorLoop(ctx)
 	b.startWorkers(ctx)
 
 	b.startLogLoop(ctx)
+	b.startTimeseriesLoop(ctx)
 	b.startStatusLoop(ctx)
 	b.startRateLoop(ctx)
 	b.printRatePrompt()
@@ -283,6 +608,7 @@ func (b *Blaster) start(ctx context.Context) error {
 	// wait for cancel or finished
 	select {
 	case <-ctx.Done():
+		b.metrics.setStopReason("cancelled")
 	case <-b.dataFinishedChannel:
 	}
 
@@ -311,6 +637,28 @@ func (b *Blaster) start(ctx context.Context) error {
 	return nil
 }
 
+// finished returns true once the run has started to finish, after which the rate and the number of
+// workers can't be changed.
+func (b *Blaster) finished() bool {
+	select {
+	case <-b.dataFinishedChannel:
+		return true
+	case <-b.workersFinishedChannel:
+		return true
+	default:
+		return false
+	}
+}
+
+// finish ends the run gracefully, in the same way as reaching the end of the data file. The reason
+// is recorded in the stats.
+func (b *Blaster) finish(reason string) {
+	b.finishOnce.Do(func() {
+		b.metrics.setStopReason(reason)
+		close(b.dataFinishedChannel)
+	})
+}
+
 // RegisterWorkerType registers a new worker function that can be referenced in config file by the worker-type string field.
 func (b *Blaster) RegisterWorkerType(key string, workerFunc func() Worker) {
 	b.workerTypes[key] = workerFunc
@@ -67,13 +67,13 @@ func TestPrintStatus(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	b.printStatus(false)
 
 	// another tick and the data will reach EOF, and gracefully exit
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	// wait for the start method to finish
 	must(t, <-finished)
@@ -82,7 +82,7 @@ func TestPrintStatus(t *testing.T) {
 
 	// metrics and rate prompt will print twice
 	mustMatch(t, out, 2, `Metrics\n=======\n`)
-	mustMatch(t, out, 2, `Current rate is 0 requests / second. Enter a new rate or press enter to view status.\n\nRate?`)
+	mustMatch(t, out, 2, `Current rate is 0 requests / second. Enter a command \(e.g. "rate 100"\), "help" for a list of commands, or press enter to view status.\n\nRate?`)
 
 }
 
@@ -116,7 +116,7 @@ func TestError(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	b.error(errors.New("a"))
@@ -278,7 +278,7 @@ func TestExit(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -326,7 +326,7 @@ func TestSetNil(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -363,10 +363,10 @@ func TestSuccess(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -399,10 +399,10 @@ func TestFail(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -436,7 +436,7 @@ func TestHung(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -469,7 +469,7 @@ func TestTimeout(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -503,7 +503,7 @@ func TestHardTimeout(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// start graceful exit process
@@ -536,7 +536,7 @@ func TestHardTimeoutCancel(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	b.Exit()
 
@@ -564,7 +564,7 @@ func TestCancel(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	b.Exit()
 
@@ -604,14 +604,14 @@ func TestLog(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// another tick and the data will reach EOF, and gracefully exit
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	// wait for the start method to finish
 	must(t, <-finished)
@@ -662,15 +662,15 @@ func TestResume(t *testing.T) {
 	}()
 
 	// this will skip the first item and complete the second item
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// this complete the third item
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// another tick and the data will reach EOF, and gracefully exit
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	// wait for the start method to finish
 	must(t, <-finished)
@@ -718,16 +718,16 @@ func TestPayloadVariants(t *testing.T) {
 
 	// each signal on the main channel will complete all the payload variants of an item, but
 	// itemFinishedChannel needs to be read once for each variant
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 	<-b.itemFinishedChannel
 
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 	<-b.itemFinishedChannel
 
 	// another tick and the data will reach EOF, and gracefully exit
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	// wait for the start method to finish
 	must(t, <-finished)
@@ -0,0 +1,84 @@
+package blaster
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 200
+	b.CircuitBreaker = &CircuitBreaker{Window: "1s", MinRequests: 10, FailFraction: 0.5, Pause: "100ms"}
+
+	// the target starts off down
+	down := int32(1)
+	b.SetWorker(func() Worker {
+		return &ExampleWorker{
+			SendFunc: func(ctx context.Context, self *ExampleWorker, in map[string]interface{}) (map[string]interface{}, error) {
+				if atomic.LoadInt32(&down) == 1 {
+					return nil, errors.New("fail")
+				}
+				return nil, nil
+			},
+		}
+	})
+
+	out := new(ThreadSafeBuffer)
+	b.SetOutput(out)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	waitFor := func(description string, f func(Stats) bool) Stats {
+		timeout := time.After(time.Second)
+		for {
+			stats := b.Stats()
+			if f(stats) {
+				return stats
+			}
+			select {
+			case <-timeout:
+				t.Fatalf("Timeout waiting for %s", description)
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+
+	opened := waitFor("breaker to open", func(s Stats) bool { return s.CircuitBreaker == "open" })
+
+	// while the target is down, only the probes are sent
+	<-time.After(time.Millisecond * 350)
+	stats := b.Stats()
+	if probes := stats.All.Summary.Started - opened.All.Summary.Started; probes > 5 {
+		t.Fatalf("Expected only a few probes while the breaker was open, got %d", probes)
+	}
+	if stats.CircuitBreakerTrips != 1 {
+		t.Fatalf("Expected the breaker to trip once, got %d", stats.CircuitBreakerTrips)
+	}
+	if !regexp.MustCompile(`Circuit breaker:\s+(open|half-open) \(tripped 1 times\)`).MatchString(stats.String()) {
+		t.Fatal("Unexpected stat string:", stats.String())
+	}
+
+	// when the target recovers, the next probe closes the breaker
+	atomic.StoreInt32(&down, 0)
+	waitFor("breaker to close", func(s Stats) bool { return s.CircuitBreaker == "closed" })
+	waitFor("successful items", func(s Stats) bool { return s.All.Summary.Success > 10 })
+
+	close(b.dataFinishedChannel)
+	must(t, <-finished)
+
+	mustMatch(t, out, 1, `\QCircuit breaker open\E`)
+	mustMatch(t, out, 1, `\QCircuit breaker closed\E`)
+
+	b.Exit()
+
+}
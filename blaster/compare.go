@@ -0,0 +1,238 @@
+package blaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// Tolerances sets the changes between two runs that Compare allows before flagging a regression.
+type Tolerances struct {
+	// Throughput is the largest allowed fractional decrease in throughput (finished requests per
+	// second), e.g. 0.05 for 5%.
+	Throughput float64
+
+	// Success is the largest allowed decrease in the success fraction, e.g. 0.01 for one percentage
+	// point.
+	Success float64
+
+	// Latency is the largest allowed fractional increase in each latency percentile, e.g. 0.1 for 10%.
+	Latency float64
+}
+
+// DefaultTolerances are the tolerances used by the compare command if none are specified.
+var DefaultTolerances = Tolerances{Throughput: 0.05, Success: 0.01, Latency: 0.1}
+
+// Difference is the change in a single metric between two runs.
+type Difference struct {
+	Section    string  // e.g. "All", "Segment 1", "All / 404" or "Segment 1 / 404"
+	Metric     string  // e.g. "Throughput", "Success" or "95th"
+	A          float64 // value in the first run (latencies are in milliseconds)
+	B          float64 // value in the second run
+	Change     float64 // fractional change, or the absolute change for fractions
+	Regression bool    // the change exceeds the tolerance
+}
+
+// Comparison is the result of Compare.
+type Comparison struct {
+	Differences []Difference
+}
+
+// Regressions returns the number of differences that were flagged as regressions.
+func (c Comparison) Regressions() int {
+	var count int
+	for _, d := range c.Differences {
+		if d.Regression {
+			count++
+		}
+	}
+	return count
+}
+
+// String returns a table of the differences.
+func (c Comparison) String() string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Section\tMetric\tA\tB\tChange\t")
+	for _, d := range c.Differences {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%+.1f%%\t", d.Section, d.Metric, formatCompareValue(d.A), formatCompareValue(d.B), d.Change*100)
+		if d.Regression {
+			fmt.Fprint(w, "REGRESSION")
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	fmt.Fprintf(buf, "\n%d regressions found.\n", c.Regressions())
+	return buf.String()
+}
+
+func formatCompareValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// LoadReport reads a report that was written in json format. See Config.ReportFile for more details.
+func LoadReport(r io.Reader) (Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return Report{}, errors.WithStack(err)
+	}
+	if report.Stats.All == nil {
+		return Report{}, errors.New("report has no stats")
+	}
+	return report, nil
+}
+
+// Compare compares the throughput, success fraction and latency percentiles of two runs: the
+// summary of all requests and each segment (matched by position), and within each of these, each
+// status (matched by name).
+// Changes for the worse beyond the tolerances are flagged as regressions.
+func Compare(a, b Report, t Tolerances) Comparison {
+
+	var c Comparison
+
+	add := func(section, metric string, va, vb, change float64, regression bool) {
+		c.Differences = append(c.Differences, Difference{section, metric, va, vb, change, regression})
+	}
+
+	// relative returns the fractional change from va to vb.
+	relative := func(va, vb float64) float64 {
+		if va == 0 {
+			return 0
+		}
+		return (vb - va) / va
+	}
+
+	latency := func(section string, pa, pb []float64, la, lb []float64) {
+		for i, p := range pa {
+			for j, q := range pb {
+				if p != q {
+					continue
+				}
+				change := relative(la[i], lb[j])
+				add(section, percentileLabel(p), la[i], lb[j], change, change > t.Latency)
+			}
+		}
+	}
+
+	summary := func(section string, sa, sb *Segment) {
+		if sa == nil || sb == nil || sa.Summary == nil || sb.Summary == nil {
+			return
+		}
+		ta, tb := throughput(sa), throughput(sb)
+		change := relative(ta, tb)
+		add(section, "Throughput", ta, tb, change, change < -t.Throughput)
+
+		fa, fb := successFraction(sa.Summary), successFraction(sb.Summary)
+		add(section, "Success", fa, fb, fb-fa, fa-fb > t.Success)
+
+		pa, la := latencies(a.Stats, sa.Summary.Percentiles, sa.Summary.NinetyFifth)
+		pb, lb := latencies(b.Stats, sb.Summary.Percentiles, sb.Summary.NinetyFifth)
+		latency(section, pa, pb, la, lb)
+
+		for _, qa := range sa.Status {
+			for _, qb := range sb.Status {
+				if qa.Status != qb.Status {
+					continue
+				}
+				status := section + " / " + qa.Status
+				add(status, "Fraction", qa.Fraction, qb.Fraction, qb.Fraction-qa.Fraction, false)
+				pa, la := latencies(a.Stats, qa.Percentiles, qa.NinetyFifth)
+				pb, lb := latencies(b.Stats, qb.Percentiles, qb.NinetyFifth)
+				latency(status, pa, pb, la, lb)
+			}
+		}
+	}
+
+	summary("All", a.Stats.All, b.Stats.All)
+
+	for i := range a.Stats.Segments {
+		if i >= len(b.Stats.Segments) {
+			break
+		}
+		summary(fmt.Sprintf("Segment %d", i+1), a.Stats.Segments[i], b.Stats.Segments[i])
+	}
+
+	return c
+}
+
+// throughput returns the number of finished requests per second.
+func throughput(s *Segment) float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Summary.Finished) / s.Duration.Seconds()
+}
+
+func successFraction(t *Total) float64 {
+	if t.Finished == 0 {
+		return 0
+	}
+	return float64(t.Success) / float64(t.Finished)
+}
+
+// latencies returns the percentiles and latencies (in milliseconds) of a total or status. Reports
+// without percentiles only have the 95th.
+func latencies(s Stats, values []time.Duration, ninetyFifth time.Duration) (percentiles []float64, ms []float64) {
+	if len(s.Percentiles) == 0 || len(values) != len(s.Percentiles) {
+		return []float64{95}, []float64{milliseconds(ninetyFifth)}
+	}
+	for _, v := range values {
+		ms = append(ms, milliseconds(v))
+	}
+	return s.Percentiles, ms
+}
+
+// compareCommand runs `blast compare a.json b.json`, and returns an error if regressions are found.
+func (b *Blaster) compareCommand(args []string) error {
+
+	// notest
+
+	flags := pflag.NewFlagSet("compare", pflag.ContinueOnError)
+	throughputFlag := flags.Float64("throughput", DefaultTolerances.Throughput, "Largest allowed fractional decrease in throughput.")
+	successFlag := flags.Float64("success", DefaultTolerances.Success, "Largest allowed decrease in the success fraction.")
+	latencyFlag := flags.Float64("latency", DefaultTolerances.Latency, "Largest allowed fractional increase in each latency percentile.")
+	if err := flags.Parse(args); err != nil {
+		return errors.WithStack(err)
+	}
+	if flags.NArg() != 2 {
+		return errors.New("usage: blast compare [--throughput=F] [--success=F] [--latency=F] a.json b.json")
+	}
+
+	load := func(filename string) (Report, error) {
+		f, err := os.Open(filename)
+		if err != nil {
+			return Report{}, errors.WithStack(err)
+		}
+		defer f.Close()
+		r, err := LoadReport(f)
+		if err != nil {
+			return Report{}, errors.Wrapf(err, "loading %s", filename)
+		}
+		return r, nil
+	}
+
+	ra, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	rb, err := load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	c := Compare(ra, rb, Tolerances{Throughput: *throughputFlag, Success: *successFlag, Latency: *latencyFlag})
+	fmt.Print(c)
+
+	if n := c.Regressions(); n > 0 {
+		return errors.Errorf("%d regressions found", n)
+	}
+	return nil
+}
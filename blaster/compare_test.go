@@ -0,0 +1,94 @@
+package blaster
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func compareReport(finished, success int64, p50, p99 time.Duration) Report {
+	total := func() *Total {
+		return &Total{Finished: finished, Success: success, Fail: finished - success, Percentiles: []time.Duration{p50, p99}}
+	}
+	status := func() []*Status {
+		return []*Status{{Status: "200", Fraction: 1, Percentiles: []time.Duration{p50, p99}}}
+	}
+	return Report{
+		Stats: Stats{
+			Percentiles: []float64{50, 99},
+			All:         &Segment{Duration: time.Second * 10, Summary: total(), Status: status()},
+			Segments:    []*Segment{{Duration: time.Second * 10, Summary: total(), Status: status()}},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+
+	a := compareReport(1000, 1000, time.Millisecond*10, time.Millisecond*50)
+
+	// within tolerances
+	c := Compare(a, compareReport(980, 975, time.Millisecond*10, time.Millisecond*54), DefaultTolerances)
+	if c.Regressions() != 0 {
+		t.Fatalf("Unexpected regressions:\n%s", c)
+	}
+	if len(c.Differences) != 14 {
+		t.Fatalf("Unexpected differences:\n%s", c)
+	}
+
+	// throughput, success and 99th regressed
+	c = Compare(a, compareReport(900, 880, time.Millisecond*10, time.Millisecond*60), DefaultTolerances)
+	if c.Regressions() != 8 {
+		t.Fatalf("Unexpected regressions:\n%s", c)
+	}
+	for _, d := range c.Differences {
+		if d.Regression != (d.Metric != "50th" && d.Metric != "Fraction") {
+			t.Fatalf("Unexpected regression %#v:\n%s", d, c)
+		}
+	}
+	out := c.String()
+	for _, expected := range []string{"Segment 1", "Throughput", "100.000", "90.000", "-10.0%", "REGRESSION", "Segment 1 / 200", "8 regressions found."} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %q in:\n%s", expected, out)
+		}
+	}
+
+	// improvements aren't regressions
+	c = Compare(a, compareReport(2000, 2000, time.Millisecond*5, time.Millisecond*25), DefaultTolerances)
+	if c.Regressions() != 0 {
+		t.Fatalf("Unexpected regressions:\n%s", c)
+	}
+
+}
+
+func TestCompareNoPercentiles(t *testing.T) {
+	report := func(ninetyFifth time.Duration) Report {
+		return Report{Stats: Stats{All: &Segment{Duration: time.Second, Summary: &Total{Finished: 10, Success: 10, NinetyFifth: ninetyFifth}}}}
+	}
+	c := Compare(report(time.Millisecond*10), report(time.Millisecond*20), DefaultTolerances)
+	if c.Regressions() != 1 || c.Differences[2].Metric != "95th" || c.Differences[2].Change != 1 {
+		t.Fatalf("Unexpected comparison:\n%s", c)
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+
+	a := compareReport(1000, 1000, time.Millisecond*10, time.Millisecond*50)
+	buf := new(bytes.Buffer)
+	must(t, json.NewEncoder(buf).Encode(a))
+
+	r, err := LoadReport(buf)
+	must(t, err)
+	if r.Stats.All.Summary.Finished != 1000 || r.Stats.All.Summary.Percentiles[1] != time.Millisecond*50 {
+		t.Fatalf("Unexpected report: %#v", r.Stats.All.Summary)
+	}
+
+	if _, err := LoadReport(strings.NewReader("{}")); err == nil || err.Error() != "report has no stats" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := LoadReport(strings.NewReader("a")); err == nil {
+		t.Fatal("Expected error")
+	}
+
+}
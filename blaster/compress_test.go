@@ -0,0 +1,84 @@
+package blaster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipData(t *testing.T, s string) []byte {
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	w.Write([]byte(s))
+	must(t, w.Close())
+	return buf.Bytes()
+}
+
+func zstdData(t *testing.T, s string) []byte {
+	buf := new(bytes.Buffer)
+	w, err := zstd.NewWriter(buf)
+	must(t, err)
+	w.Write([]byte(s))
+	must(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestOpenDataCompressed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+
+	csv := "a,b\n1,2\n"
+	jsonl := `{"a":"1","b":"2"}` + "\n"
+	files := map[string][]byte{
+		"data.csv.gz":    gzipData(t, csv),
+		"data.csv.zst":   zstdData(t, csv),
+		"data.jsonl.gz":  gzipData(t, jsonl),
+		"gzip-magic":     gzipData(t, csv),
+		"zstd-magic":     zstdData(t, csv),
+		"plain":          []byte(csv),
+		"data.jsonl.zst": zstdData(t, jsonl),
+	}
+
+	for name, contents := range files {
+		must(t, ioutil.WriteFile(filepath.Join(dir, name), contents, 0666))
+
+		b := New(ctx, cancel)
+		must(t, b.openData(ctx, filepath.Join(dir, name), true))
+		fields, err := b.readData()
+		must(t, err)
+		if !reflect.DeepEqual(fields, map[string]string{"a": "1", "b": "2"}) {
+			t.Fatalf("Unexpected fields for %s: %v", name, fields)
+		}
+		b.Exit()
+	}
+
+	b := New(ctx, cancel)
+	b.gcs = mapOpener{"bucket/data": string(files["zstd-magic"])}
+	must(t, b.openData(ctx, "gs://bucket/data", true))
+	if !reflect.DeepEqual(b.Headers, []string{"a", "b"}) {
+		t.Fatalf("Unexpected headers: %v", b.Headers)
+	}
+
+	b = New(ctx, cancel)
+	must(t, b.openData(ctx, string(gzipData(t, csv))+"\n", true))
+	if !reflect.DeepEqual(b.Headers, []string{"a", "b"}) {
+		t.Fatalf("Unexpected headers: %v", b.Headers)
+	}
+
+	b = New(ctx, cancel)
+	must(t, ioutil.WriteFile(filepath.Join(dir, "bad.gz"), []byte(csv), 0666))
+	if err := b.openData(ctx, filepath.Join(dir, "bad.gz"), true); err == nil {
+		t.Fatal("Expected error")
+	}
+}
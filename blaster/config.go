@@ -23,7 +23,7 @@ import (
 
 // Config provides all the standard config options. Use the Initialise method to configure with a provided Config.
 type Config struct {
-	// Data sets the the data file to load. If none is specified, the worker will be called repeatedly until interrupted (useful for load testing). Load a local file or stream directly from a GCS bucket with `gs://{bucket}/{filename}.csv`. Data should be in csv format, and if `headers` is not specified the first record will be used as the headers. If a newline character is found, this string is read as the data.
+	// Data sets the data source. If none is specified, the worker will be called repeatedly until interrupted (useful for load testing). Data should be in csv format, and if `headers` is not specified the first record will be used as the headers. Json lines data is also supported (see `data-format`). Load a local file, or stream from a GCS bucket with `gs://{bucket}/{filename}.csv`. A glob pattern (e.g. `shards/*.csv`), a directory or a GCS prefix ending in a slash (e.g. `gs://{bucket}/{prefix}/`) reads several files one after another. Each file must have the same headers, the status shows the current file, and the log records the file of each item. A file that exists is read as is, even if its name contains glob characters. A `http://` or `https://` url is streamed. If the connection drops and the server supports range requests, blast resumes where it stopped, otherwise the run ends with an error. Use `-` to read from stdin (interactive commands are then disabled). Gzip and zstd compressed data is decompressed, detected by the extension (`.gz` or `.zst`) or the first bytes of the data. Rows can be read from a database with `{driver}://{dsn}?query={query}`, e.g. `sqlite://jobs.db?query=SELECT id, name FROM jobs ORDER BY id`, and the column names are used as the headers. The query must be the last parameter, and is url decoded, so a literal `+` or `%` must be encoded as `%2B` or `%25`. The `skip-rows`, `data-format` and `csv` options can't be used with a database. The sqlite driver is included, and other database/sql drivers can be registered when using blast from code. If a newline character is found, this string is read as the data.
 	Data string `mapstructure:"data" json:"data"`
 
 	// Log sets the filename of the log file to create / append to.
@@ -67,6 +67,84 @@ type Config struct {
 
 	// Quiet instructs the tool to prevent interactive features. No summary is printed during operation and the rate cannot be changed interactively.
 	Quiet bool `mapstructure:"quiet" json:"quiet"`
+
+	// RateSchedule sets a list of stages that change the rate automatically. Each stage has a target `rate`, a `duration` (e.g. `30s`) and a `shape`: `step` (the default) jumps straight to the target rate, and `linear` ramps from the previous rate to the target rate over the duration. A new metrics segment is created for each stage, and after the final stage the rate is held. When setting this by command line flag or environment variable, use a json encoded string.
+	RateSchedule []RateStage `mapstructure:"rate-schedule" json:"rate-schedule"`
+
+	// CorrectLatency measures latency from the time each item should have been sent, rather than from the time a worker picks it up. Ticks that find no idle worker are queued instead of skipped, so the latency percentiles include time spent waiting for a worker. Queued ticks are shown as delayed rather than missed in the status. At most 100000 ticks are queued, and further ticks are dropped and counted as missed. This corrects for coordinated omission when the target is overloaded.
+	CorrectLatency bool `mapstructure:"correct-latency" json:"correct-latency"`
+
+	// Arrival sets the distribution of the intervals between requests: `constant` (the default) sends at a fixed interval, `poisson` draws exponentially distributed intervals to simulate independent users, and `uniform-jitter` draws intervals uniformly between 0.5 and 1.5 times the fixed interval. The mean interval is the same for each distribution, so the actual rate converges on the desired rate.
+	Arrival string `mapstructure:"arrival" json:"arrival"`
+
+	// ClosedLoop runs without a rate limit: each worker requests the next item as soon as its previous request has finished, so concurrency is bounded only by the number of workers. Use this to find the maximum throughput of the target - the actual rate is reported in the metrics. The `rate` option is ignored, and `rate-schedule` can't be used in closed-loop mode.
+	ClosedLoop bool `mapstructure:"closed-loop" json:"closed-loop"`
+
+	// Search runs a capacity search: the rate is raised segment by segment until a segment fails, then a binary search finds the highest sustainable rate, which is reported in the final output. A segment fails if the fraction of failed requests exceeds `fail-fraction` (default `0.01`), the 95th percentile latency exceeds `p95` (if set), or the actual rate falls more than 10% short of the desired rate. Each segment lasts for `duration` (e.g. `30s`, required). The first segment uses the `start` rate (default: the `rate` option), and the rate is multiplied by `factor` (default `2`) until a segment fails. The search stops when the gap between the highest passing rate and the lowest failing rate is less than `precision` (default `0.05`) of the failing rate, and the run then finishes. If every segment fails, the rate is halved until it falls below 1% of the `start` rate, and then the search finishes with no sustainable rate. Search can't be used with `rate-schedule` or in closed-loop mode. When setting this by command line flag or environment variable, use a json encoded string.
+	Search *Search `mapstructure:"search" json:"search"`
+
+	// Duration sets the length of the run, e.g. `30s` or `5m`. When the duration is reached, workers finish their current items and the run ends cleanly, as it does at the end of the data file. If omitted, blast runs until the data is exhausted or it is interrupted.
+	Duration string `mapstructure:"duration" json:"duration"`
+
+	// MaxRequests ends the run cleanly after this many requests have been sent. (Default: no limit).
+	MaxRequests int64 `mapstructure:"max-requests" json:"max-requests"`
+
+	// MaxFailures ends the run cleanly when this many requests have failed. (Default: no limit).
+	MaxFailures int64 `mapstructure:"max-failures" json:"max-failures"`
+
+	// MaxFailFraction ends the run cleanly when the fraction of failed requests exceeds this value, e.g. `0.05`. To avoid stopping on the first few results, the fraction is only checked after 100 requests have finished. (Default: no limit).
+	MaxFailFraction float64 `mapstructure:"max-fail-fraction" json:"max-fail-fraction"`
+
+	// Retry sets a retry policy for failed items. Failed items are retried up to `max-attempts` times in total (default `3`), after an exponential backoff: the first retry waits for `backoff` (default `100ms`), and each subsequent delay is multiplied by `multiplier` (default `2`) up to `max-backoff` (default `10s`). Each delay is randomised by up to the `jitter` fraction (default `0.2`). If `statuses` is specified, only items that failed with one of these statuses are retried. Retries are rate limited in the same way as fresh items, and the run waits for outstanding retries before finishing at the end of the data. Every attempt counts as a request in the metrics, and retries are also counted separately. When a log is written, an `attempt` column is included. When setting this by command line flag or environment variable, use a json encoded string.
+	Retry *Retry `mapstructure:"retry" json:"retry"`
+
+	// CircuitBreaker pauses sending when the target is failing. The breaker trips when more than `fail-fraction` (default `0.5`) of the requests in a sliding `window` (default `10s`) have failed, as long as the window contains at least `min-requests` (default `20`) requests. While the breaker is open, ticks are skipped without reading any data, so no items are used up. After `pause` (default `5s`) a single probe request is sent: if it succeeds the breaker closes and sending resumes, and if it fails the breaker stays open for another pause. With `payload-variants`, only the first variant of the probe item is the probe: the other variants are still sent, but their results don't change the state of the breaker. The `window` must be at least `1ms`. The state of the breaker is shown in the status output. When setting this by command line flag or environment variable, use a json encoded string.
+	CircuitBreaker *CircuitBreaker `mapstructure:"circuit-breaker" json:"circuit-breaker"`
+
+	// Percentiles sets the latency percentiles that are reported for each segment and status, e.g. `[50, 99, 99.9]`. Latencies are recorded in a high dynamic range histogram, so every request is counted and the tail percentiles are accurate. The maximum latency is always reported. (Default: `[50, 90, 95, 99, 99.9]`). When setting this by command line flag or environment variable, use a json encoded string.
+	Percentiles []float64 `mapstructure:"percentiles" json:"percentiles"`
+
+	// ReportFile sets the filename of a report that is written at the end of the run. The report contains the final metrics (every segment, total and status), the config, the start and end times and the reason the run stopped. Durations in the json report are in nanoseconds.
+	ReportFile string `mapstructure:"report-file" json:"report-file"`
+
+	// OutputFormat sets the format of the report file: `text` or `json`. (Default: `text`).
+	OutputFormat string `mapstructure:"output-format" json:"output-format"`
+
+	// MetricsAddr sets the address of a listener that serves live metrics in Prometheus exposition format at `/metrics`, e.g. `:9090`. Request counters and latency histograms are labelled by segment and status, and busy workers, skipped items and the desired and actual rates are also included.
+	MetricsAddr string `mapstructure:"metrics-addr" json:"metrics-addr"`
+
+	// ControlAddr sets the address of a local HTTP control server, e.g. `localhost:8081`. Use `GET /stats` to get the stats as json, `POST /rate?rate=N` to change the rate, `POST /workers?count=N` to change the number of workers, `POST /pause` and `POST /resume` to pause and resume sending, and `POST /stop` to end the run gracefully. The server has no authentication, so bind it to a local address.
+	ControlAddr string `mapstructure:"control-addr" json:"control-addr"`
+
+	// TimeseriesFile sets the filename of a time-series file, which is written with one row per interval (see `timeseries-interval`) during the run. Each row contains the time, the current segment and its desired rate, the number of requests started, finished, succeeded and failed during the interval, the number of busy workers, and the mean, percentile (see `percentiles`) and maximum latency of requests that finished during the interval. If the filename ends in `.json` or `.jsonl` rows are written as json lines (with durations in nanoseconds), otherwise as csv (with durations in milliseconds).
+	TimeseriesFile string `mapstructure:"timeseries-file" json:"timeseries-file"`
+
+	// TimeseriesInterval sets the interval between rows in the time-series file, e.g. `1s` or `500ms`. (Default: 1s).
+	TimeseriesInterval string `mapstructure:"timeseries-interval" json:"timeseries-interval"`
+
+	// HTMLReport sets the filename of a self-contained html report that is written at the end of the run. The report contains rate, throughput, latency percentile and error rate charts over time (recorded every `timeseries-interval`), the metrics of every segment as printed in the status, and the config. It has no external dependencies, so it can be viewed offline or attached to a ticket.
+	HTMLReport string `mapstructure:"html-report" json:"html-report"`
+
+	// DataFormat sets the format of the data: `csv` or `jsonl` (json lines). If omitted, files ending `.jsonl` or `.ndjson` are read as json lines, and everything else as csv. Each line of json lines data is a json object, and each top-level key is available as a template field. Nested objects and arrays (and numbers and booleans) are available as json strings, and `headers` is not used.
+	DataFormat string `mapstructure:"data-format" json:"data-format"`
+
+	// DataLoop replays the data source several times, e.g. to run a load test from a corpus of IDs. Set to the number of passes over the data, or `-1` to loop until the run is stopped by `duration`, `max-requests` or by the user. The data is re-opened at the start of each pass, so this can't be used when the data is read from stdin (unless `data-order` is `random`). The number of completed passes is shown in the status. (Default: 0 - the data is read once).
+	DataLoop int `mapstructure:"data-loop" json:"data-loop"`
+
+	// DataOrder sets the order the data is sent in: `file` (the order of the data source), `shuffle` or `random`. `shuffle` reads `data-buffer` items ahead and sends a random one of them, so each item is sent once per pass in a shuffled order without reading the whole data source into memory. `random` samples items at random (with replacement) from the data: a uniform sample of up to `data-buffer` items is read into memory, and each pass sends as many items as the sample holds. (Default: file).
+	DataOrder string `mapstructure:"data-order" json:"data-order"`
+
+	// DataBuffer sets the number of items held in memory when `data-order` is `shuffle` or `random`. (Default: 10000).
+	DataBuffer int `mapstructure:"data-buffer" json:"data-buffer"`
+
+	// Generate adds generated columns to each item, so synthetic data can be sent without a data file. Each generator has a `name` (the name of the field) and a `type`: `sequence` counts from `start` (default `0`) in increments of `step` (default `1`), `uuid` is a random uuid, `int` is a random whole number between `min` and `max` (inclusive), `float` is a random number between `min` and `max` (rounded to `decimals` places if set), `choice` picks one of `choices` at random (weighted by `weights` if set), and `timestamp` is the current time formatted with `format`: a Go time layout (default RFC3339), `unix` or `unix-ms`. Generated columns are available as template fields in the same way as data columns, and may be used with `data` (a generated column replaces a data column with the same name). They can be logged with `log-data`, and are included in the hash used by `resume`, so only `sequence` columns can be used in resume mode (the other types change between runs, so no items would be skipped). When setting this by command line flag or environment variable, use a json encoded string.
+	Generate []Generator `mapstructure:"generate" json:"generate"`
+
+	// CSV sets how csv data is read: `comma` sets the field delimiter (e.g. `;`, or `\t` for tab separated data), `comment` sets a character that starts a comment line (e.g. `#`), `lazy-quotes` allows quotes to appear in unquoted fields and non-doubled quotes in quoted fields, `trim-leading-space` ignores leading white space in fields, and `fields-per-record` sets the number of fields in each record (`0`, the default, requires every record to have the same number of fields as the first, and `-1` allows records with a variable number of fields, where missing fields are empty). When setting this by command line flag or environment variable, use a json encoded string, e.g. `{"comma": ";"}`.
+	CSV *CSV `mapstructure:"csv" json:"csv"`
+
+	// SkipRows skips this number of lines at the start of the data (e.g. a preamble before the headers). When reading several files, the lines are skipped at the start of each file.
+	SkipRows int `mapstructure:"skip-rows" json:"skip-rows"`
 }
 
 // LoadConfig parses command line flags and loads a config file from disk. A Config is returned which may be used with the Initialise method to complete configuration.
@@ -117,6 +195,32 @@ func (b *Blaster) setupFlags() (dryRunFlag bool, configFlag string) {
 	pflag.String("payload-variants", "", "`` "+doc["Config.PayloadVariants"])
 	pflag.String("worker-variants", "", "`` "+doc["Config.WorkerVariants"])
 	pflag.Bool("quiet", false, "`` "+doc["Config.Quiet"])
+	pflag.String("rate-schedule", "", "`` "+doc["Config.RateSchedule"])
+	pflag.Bool("correct-latency", false, "`` "+doc["Config.CorrectLatency"])
+	pflag.String("arrival", "constant", "`` "+doc["Config.Arrival"])
+	pflag.Bool("closed-loop", false, "`` "+doc["Config.ClosedLoop"])
+	pflag.String("search", "", "`` "+doc["Config.Search"])
+	pflag.String("duration", "", "`` "+doc["Config.Duration"])
+	pflag.Int64("max-requests", 0, "`` "+doc["Config.MaxRequests"])
+	pflag.Int64("max-failures", 0, "`` "+doc["Config.MaxFailures"])
+	pflag.Float64("max-fail-fraction", 0, "`` "+doc["Config.MaxFailFraction"])
+	pflag.String("retry", "", "`` "+doc["Config.Retry"])
+	pflag.String("circuit-breaker", "", "`` "+doc["Config.CircuitBreaker"])
+	pflag.String("percentiles", "", "`` "+doc["Config.Percentiles"])
+	pflag.String("report-file", "", "`` "+doc["Config.ReportFile"])
+	pflag.String("output-format", "text", "`` "+doc["Config.OutputFormat"])
+	pflag.String("metrics-addr", "", "`` "+doc["Config.MetricsAddr"])
+	pflag.String("control-addr", "", "`` "+doc["Config.ControlAddr"])
+	pflag.String("timeseries-file", "", "`` "+doc["Config.TimeseriesFile"])
+	pflag.String("timeseries-interval", "", "`` "+doc["Config.TimeseriesInterval"])
+	pflag.String("html-report", "", "`` "+doc["Config.HTMLReport"])
+	pflag.String("data-format", "", "`` "+doc["Config.DataFormat"])
+	pflag.Int("data-loop", 0, "`` "+doc["Config.DataLoop"])
+	pflag.String("data-order", "", "`` "+doc["Config.DataOrder"])
+	pflag.Int("data-buffer", 10000, "`` "+doc["Config.DataBuffer"])
+	pflag.String("generate", "", "`` "+doc["Config.Generate"])
+	pflag.String("csv", "", "`` "+doc["Config.CSV"])
+	pflag.Int("skip-rows", 0, "`` "+doc["Config.SkipRows"])
 
 	pflag.Parse()
 
@@ -166,6 +270,32 @@ func (b *Blaster) setupViper(configFlag string) error {
 	b.viper.SetDefault("payload-variants", []map[string]string{{}})
 	b.viper.SetDefault("worker-variants", []map[string]string{{}})
 	b.viper.SetDefault("quiet", false)
+	b.viper.SetDefault("rate-schedule", []RateStage{})
+	b.viper.SetDefault("correct-latency", false)
+	b.viper.SetDefault("arrival", "constant")
+	b.viper.SetDefault("closed-loop", false)
+	b.viper.SetDefault("search", nil)
+	b.viper.SetDefault("duration", "")
+	b.viper.SetDefault("max-requests", 0)
+	b.viper.SetDefault("max-failures", 0)
+	b.viper.SetDefault("max-fail-fraction", 0.0)
+	b.viper.SetDefault("retry", nil)
+	b.viper.SetDefault("circuit-breaker", nil)
+	b.viper.SetDefault("percentiles", []float64{50, 90, 95, 99, 99.9})
+	b.viper.SetDefault("report-file", "")
+	b.viper.SetDefault("output-format", "text")
+	b.viper.SetDefault("metrics-addr", "")
+	b.viper.SetDefault("control-addr", "")
+	b.viper.SetDefault("timeseries-file", "")
+	b.viper.SetDefault("timeseries-interval", "")
+	b.viper.SetDefault("html-report", "")
+	b.viper.SetDefault("data-format", "")
+	b.viper.SetDefault("data-loop", 0)
+	b.viper.SetDefault("data-order", "")
+	b.viper.SetDefault("data-buffer", 10000)
+	b.viper.SetDefault("generate", []Generator{})
+	b.viper.SetDefault("csv", nil)
+	b.viper.SetDefault("skip-rows", 0)
 
 	b.viper.SetEnvPrefix("blast")
 	b.viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -262,18 +392,157 @@ func (b *Blaster) unmarshalConfig(c *Config) error {
 	if err := b.viper.UnmarshalKey("quiet", &c.Quiet); err != nil {
 		return errors.WithStack(err)
 	}
+	if s := b.viper.GetString("rate-schedule"); s != "" {
+		// if array type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.RateSchedule); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("rate-schedule", &c.RateSchedule); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := b.viper.UnmarshalKey("correct-latency", &c.CorrectLatency); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("arrival", &c.Arrival); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("closed-loop", &c.ClosedLoop); err != nil {
+		return errors.WithStack(err)
+	}
+	if s := b.viper.GetString("search"); s != "" {
+		// if struct type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.Search); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("search", &c.Search); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := b.viper.UnmarshalKey("duration", &c.Duration); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("max-requests", &c.MaxRequests); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("max-failures", &c.MaxFailures); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("max-fail-fraction", &c.MaxFailFraction); err != nil {
+		return errors.WithStack(err)
+	}
+	if s := b.viper.GetString("retry"); s != "" {
+		// if struct type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.Retry); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("retry", &c.Retry); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if s := b.viper.GetString("circuit-breaker"); s != "" {
+		// if struct type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.CircuitBreaker); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("circuit-breaker", &c.CircuitBreaker); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if s := b.viper.GetString("percentiles"); s != "" {
+		// if array type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.Percentiles); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("percentiles", &c.Percentiles); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := b.viper.UnmarshalKey("report-file", &c.ReportFile); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("output-format", &c.OutputFormat); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("metrics-addr", &c.MetricsAddr); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("control-addr", &c.ControlAddr); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("timeseries-file", &c.TimeseriesFile); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("timeseries-interval", &c.TimeseriesInterval); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("html-report", &c.HTMLReport); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("data-format", &c.DataFormat); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("data-loop", &c.DataLoop); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("data-order", &c.DataOrder); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := b.viper.UnmarshalKey("data-buffer", &c.DataBuffer); err != nil {
+		return errors.WithStack(err)
+	}
+	if s := b.viper.GetString("generate"); s != "" {
+		// if array type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.Generate); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("generate", &c.Generate); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if s := b.viper.GetString("csv"); s != "" {
+		// if struct type data is actually a string, unmarshal it from json
+		if err := json.Unmarshal([]byte(s), &c.CSV); err != nil {
+			return errors.WithStack(err)
+		}
+	} else {
+		if err := b.viper.UnmarshalKey("csv", &c.CSV); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := b.viper.UnmarshalKey("skip-rows", &c.SkipRows); err != nil {
+		return errors.WithStack(err)
+	}
 	return nil
 }
 
 // Initialise configures the Blaster with config options in a provided Config
 func (b *Blaster) Initialise(ctx context.Context, c Config) error {
 
+	// store the config so it can be included in the report
+	b.config = &c
+
 	b.Rate = c.Rate
 	if c.Workers != 0 {
 		b.Workers = c.Workers
 	}
 	b.Quiet = c.Quiet
 	b.Resume = c.Resume
+	b.CorrectLatency = c.CorrectLatency
+	b.ClosedLoop = c.ClosedLoop
+
+	if c.Arrival != "" {
+		if _, ok := arrivals[c.Arrival]; !ok {
+			return errors.Errorf("arrival %s not found", c.Arrival)
+		}
+		b.Arrival = c.Arrival
+	}
 
 	if len(c.LogData) > 0 {
 		b.LogData = c.LogData
@@ -293,6 +562,106 @@ func (b *Blaster) Initialise(ctx context.Context, c Config) error {
 		b.Headers = c.Headers
 	}
 
+	if len(c.RateSchedule) > 0 {
+		for _, stage := range c.RateSchedule {
+			if _, err := stage.validate(); err != nil {
+				return err
+			}
+		}
+		b.RateSchedule = c.RateSchedule
+	}
+
+	if b.ClosedLoop && len(b.RateSchedule) > 0 {
+		return errors.New("rate-schedule can't be used in closed-loop mode")
+	}
+
+	if c.Duration != "" {
+		d, err := time.ParseDuration(c.Duration)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d <= 0 {
+			return errors.New("duration must be positive")
+		}
+		b.Duration = d
+	}
+
+	if c.MaxRequests < 0 || c.MaxFailures < 0 {
+		return errors.New("max-requests and max-failures must not be negative")
+	}
+	b.MaxRequests = c.MaxRequests
+	b.MaxFailures = c.MaxFailures
+
+	if c.MaxFailFraction < 0 || c.MaxFailFraction > 1 {
+		return errors.New("max-fail-fraction must be between 0 and 1")
+	}
+	b.MaxFailFraction = c.MaxFailFraction
+
+	if c.Search != nil {
+		if _, err := c.Search.parse(b.Rate); err != nil {
+			return err
+		}
+		if b.ClosedLoop || len(b.RateSchedule) > 0 {
+			return errors.New("search can't be used with rate-schedule or in closed-loop mode")
+		}
+		b.Search = c.Search
+	}
+
+	if c.Retry != nil {
+		if _, err := c.Retry.parse(); err != nil {
+			return err
+		}
+		b.Retry = c.Retry
+	}
+
+	if c.CircuitBreaker != nil {
+		if _, err := c.CircuitBreaker.parse(); err != nil {
+			return err
+		}
+		b.CircuitBreaker = c.CircuitBreaker
+	}
+
+	if len(c.Generate) > 0 {
+		if _, err := parseGenerators(c.Generate); err != nil {
+			return err
+		}
+		if c.Resume && !resumableGenerators(c.Generate) {
+			return errors.New("only sequence generated columns can be used in resume mode")
+		}
+		b.Generate = c.Generate
+	}
+
+	if len(c.Percentiles) > 0 {
+		for _, p := range c.Percentiles {
+			if p <= 0 || p > 100 {
+				return errors.Errorf("percentile %v must be between 0 and 100", p)
+			}
+		}
+		b.Percentiles = c.Percentiles
+	}
+
+	switch c.OutputFormat {
+	case "", "text", "json":
+		b.OutputFormat = c.OutputFormat
+	default:
+		return errors.Errorf("output-format %s not found", c.OutputFormat)
+	}
+	b.reportFile = c.ReportFile
+	b.htmlReport = c.HTMLReport
+	b.MetricsAddr = c.MetricsAddr
+	b.ControlAddr = c.ControlAddr
+
+	if c.TimeseriesInterval != "" {
+		d, err := time.ParseDuration(c.TimeseriesInterval)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d <= 0 {
+			return errors.New("timeseries-interval must be positive")
+		}
+		b.TimeseriesInterval = d
+	}
+
 	if c.Timeout > 0 {
 		b.SetTimeout(time.Duration(c.Timeout) * time.Millisecond)
 	}
@@ -313,6 +682,50 @@ func (b *Blaster) Initialise(ctx context.Context, c Config) error {
 		return err
 	}
 
+	switch c.DataFormat {
+	case "", "csv", "jsonl":
+		b.DataFormat = c.DataFormat
+	default:
+		return errors.Errorf("data-format %s not found", c.DataFormat)
+	}
+
+	if c.CSV != nil {
+		if _, _, err := c.CSV.parse(); err != nil {
+			return err
+		}
+		b.CSV = c.CSV
+	}
+
+	if c.SkipRows < 0 {
+		return errors.New("skip-rows must not be negative")
+	}
+	b.SkipRows = c.SkipRows
+
+	if c.DataLoop < -1 {
+		return errors.New("data-loop must be -1 or more")
+	}
+	if c.DataLoop != 0 && c.Resume {
+		return errors.New("data-loop can't be used in resume mode")
+	}
+	if c.DataLoop != 0 && c.Data == "-" && c.DataOrder != "random" {
+		return errors.New("data-loop can't be used with stdin data unless data-order is random")
+	}
+	b.DataLoop = c.DataLoop
+
+	switch c.DataOrder {
+	case "", "file", "shuffle", "random":
+		b.DataOrder = c.DataOrder
+	default:
+		return errors.Errorf("data-order %s not found", c.DataOrder)
+	}
+
+	if c.DataBuffer < 0 {
+		return errors.New("data-buffer must be positive")
+	}
+	if c.DataBuffer > 0 {
+		b.DataBuffer = c.DataBuffer
+	}
+
 	if c.Data != "" {
 		// notest
 		if err := b.openData(ctx, c.Data, len(c.Headers) == 0); err != nil {
@@ -327,5 +740,12 @@ func (b *Blaster) Initialise(ctx context.Context, c Config) error {
 		}
 	}
 
+	if c.TimeseriesFile != "" {
+		// notest
+		if err := b.openTimeseries(c.TimeseriesFile); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
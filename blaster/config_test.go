@@ -105,6 +105,69 @@ func TestBlaster_unmarshalConfig(t *testing.T) {
 			return c.PayloadVariants[0]["e"] == "f" && c.PayloadVariants[1]["g"] == "h", nil
 		}},
 		"quiet": {"quiet", true, func(c Config) (bool, error) { return c.Quiet, nil }},
+		"rate schedule native": {"rate-schedule", []map[string]interface{}{{"rate": 1, "duration": "2s"}, {"rate": 3, "duration": "4s", "shape": "linear"}}, func(c Config) (bool, error) {
+			return c.RateSchedule[0].Rate == 1 && c.RateSchedule[0].Duration == "2s" && c.RateSchedule[1].Shape == "linear", nil
+		}},
+		"correct latency": {"correct-latency", true, func(c Config) (bool, error) { return c.CorrectLatency, nil }},
+		"arrival":         {"arrival", "poisson", func(c Config) (bool, error) { return c.Arrival == "poisson", nil }},
+		"closed loop":     {"closed-loop", true, func(c Config) (bool, error) { return c.ClosedLoop, nil }},
+		"rate schedule json": {"rate-schedule", `[{"rate":5,"duration":"6s","shape":"step"}]`, func(c Config) (bool, error) {
+			return c.RateSchedule[0].Rate == 5 && c.RateSchedule[0].Duration == "6s" && c.RateSchedule[0].Shape == "step", nil
+		}},
+		"duration":          {"duration", "1m", func(c Config) (bool, error) { return c.Duration == "1m", nil }},
+		"max requests":      {"max-requests", 10, func(c Config) (bool, error) { return c.MaxRequests == 10, nil }},
+		"max failures":      {"max-failures", 5, func(c Config) (bool, error) { return c.MaxFailures == 5, nil }},
+		"max fail fraction": {"max-fail-fraction", 0.5, func(c Config) (bool, error) { return c.MaxFailFraction == 0.5, nil }},
+		"retry native": {"retry", map[string]interface{}{"max-attempts": 5, "backoff": "1s", "statuses": []string{"503"}}, func(c Config) (bool, error) {
+			return c.Retry.MaxAttempts == 5 && c.Retry.Backoff == "1s" && c.Retry.Statuses[0] == "503", nil
+		}},
+		"retry json": {"retry", `{"max-backoff":"2s","multiplier":3,"jitter":0.5}`, func(c Config) (bool, error) {
+			return c.Retry.MaxBackoff == "2s" && c.Retry.Multiplier == 3 && c.Retry.Jitter == 0.5, nil
+		}},
+		"circuit breaker native": {"circuit-breaker", map[string]interface{}{"window": "1s", "fail-fraction": 0.2}, func(c Config) (bool, error) {
+			return c.CircuitBreaker.Window == "1s" && c.CircuitBreaker.FailFraction == 0.2, nil
+		}},
+		"circuit breaker json": {"circuit-breaker", `{"min-requests":5,"pause":"2s"}`, func(c Config) (bool, error) {
+			return c.CircuitBreaker.MinRequests == 5 && c.CircuitBreaker.Pause == "2s", nil
+		}},
+		"percentiles native": {"percentiles", []float64{50, 99.9}, func(c Config) (bool, error) {
+			return len(c.Percentiles) == 2 && c.Percentiles[1] == 99.9, nil
+		}},
+		"percentiles json": {"percentiles", `[90,99]`, func(c Config) (bool, error) {
+			return len(c.Percentiles) == 2 && c.Percentiles[0] == 90, nil
+		}},
+		"report file": {"report-file", "a", func(c Config) (bool, error) { return c.ReportFile == "a", nil }},
+		"data format": {"data-format", "jsonl", func(c Config) (bool, error) { return c.DataFormat == "jsonl", nil }},
+		"data loop":   {"data-loop", -1, func(c Config) (bool, error) { return c.DataLoop == -1, nil }},
+		"data order":  {"data-order", "shuffle", func(c Config) (bool, error) { return c.DataOrder == "shuffle", nil }},
+		"data buffer": {"data-buffer", 100, func(c Config) (bool, error) { return c.DataBuffer == 100, nil }},
+		"generate native": {"generate", []map[string]interface{}{{"name": "a", "type": "choice", "choices": []string{"x", "y"}}}, func(c Config) (bool, error) {
+			return len(c.Generate) == 1 && c.Generate[0].Type == "choice" && len(c.Generate[0].Choices) == 2, nil
+		}},
+		"generate json": {"generate", `[{"name":"a","type":"int","min":1,"max":10}]`, func(c Config) (bool, error) {
+			return len(c.Generate) == 1 && c.Generate[0].Name == "a" && c.Generate[0].Max == 10, nil
+		}},
+		"output format":   {"output-format", "json", func(c Config) (bool, error) { return c.OutputFormat == "json", nil }},
+		"metrics addr":    {"metrics-addr", ":9090", func(c Config) (bool, error) { return c.MetricsAddr == ":9090", nil }},
+		"control addr":    {"control-addr", ":8081", func(c Config) (bool, error) { return c.ControlAddr == ":8081", nil }},
+		"html report":     {"html-report", "a.html", func(c Config) (bool, error) { return c.HTMLReport == "a.html", nil }},
+		"timeseries file": {"timeseries-file", "a.csv", func(c Config) (bool, error) { return c.TimeseriesFile == "a.csv", nil }},
+		"timeseries interval": {"timeseries-interval", "500ms", func(c Config) (bool, error) {
+			return c.TimeseriesInterval == "500ms", nil
+		}},
+		"csv native": {"csv", map[string]interface{}{"comma": ";", "lazy-quotes": true}, func(c Config) (bool, error) {
+			return c.CSV.Comma == ";" && c.CSV.LazyQuotes, nil
+		}},
+		"csv json": {"csv", `{"comma":"\t","fields-per-record":-1}`, func(c Config) (bool, error) {
+			return c.CSV.Comma == "\t" && c.CSV.FieldsPerRecord == -1, nil
+		}},
+		"skip rows": {"skip-rows", 2, func(c Config) (bool, error) { return c.SkipRows == 2, nil }},
+		"search native": {"search", map[string]interface{}{"duration": "2s", "fail-fraction": 0.1, "p95": "100ms"}, func(c Config) (bool, error) {
+			return c.Search.Duration == "2s" && c.Search.FailFraction == 0.1 && c.Search.P95 == "100ms", nil
+		}},
+		"search json": {"search", `{"start":5,"factor":3,"duration":"6s","precision":0.1}`, func(c Config) (bool, error) {
+			return c.Search.Start == 5 && c.Search.Factor == 3 && c.Search.Duration == "6s" && c.Search.Precision == 0.1, nil
+		}},
 	}
 	for name, test := range tests {
 		run(name, test)
@@ -135,6 +198,15 @@ func TestBlaster_Initialise(t *testing.T) {
 		"resume": {Config{Resume: true}, func(b *Blaster) (bool, error) {
 			return b.Resume == true, nil
 		}},
+		"csv": {Config{CSV: &CSV{Comment: "#"}, SkipRows: 1}, func(b *Blaster) (bool, error) {
+			return b.CSV.Comment == "#" && b.SkipRows == 1, nil
+		}},
+		"generate": {Config{Generate: []Generator{{Name: "a", Type: "uuid"}}}, func(b *Blaster) (bool, error) {
+			return len(b.Generate) == 1, nil
+		}},
+		"data-loop": {Config{DataLoop: -1, DataOrder: "random", DataBuffer: 100}, func(b *Blaster) (bool, error) {
+			return b.DataLoop == -1 && b.DataOrder == "random" && b.DataBuffer == 100, nil
+		}},
 		"rate": {Config{Rate: 100}, func(b *Blaster) (bool, error) {
 			return b.Rate == 100, nil
 		}},
@@ -185,8 +257,223 @@ func TestBlaster_Initialise(t *testing.T) {
 		"quiet": {Config{Quiet: true}, func(b *Blaster) (bool, error) {
 			return b.Quiet, nil
 		}},
+		"correct-latency": {Config{CorrectLatency: true}, func(b *Blaster) (bool, error) {
+			return b.CorrectLatency, nil
+		}},
+		"arrival": {Config{Arrival: "uniform-jitter"}, func(b *Blaster) (bool, error) {
+			return b.Arrival == "uniform-jitter", nil
+		}},
+		"closed-loop": {Config{ClosedLoop: true}, func(b *Blaster) (bool, error) {
+			return b.ClosedLoop, nil
+		}},
+		"rate-schedule": {Config{RateSchedule: []RateStage{{Rate: 1, Duration: "2s"}}}, func(b *Blaster) (bool, error) {
+			return len(b.RateSchedule) == 1 && b.RateSchedule[0].Rate == 1, nil
+		}},
+		"duration": {Config{Duration: "1m"}, func(b *Blaster) (bool, error) {
+			return b.Duration == time.Minute, nil
+		}},
+		"max-requests": {Config{MaxRequests: 10}, func(b *Blaster) (bool, error) {
+			return b.MaxRequests == 10, nil
+		}},
+		"max-failures": {Config{MaxFailures: 5}, func(b *Blaster) (bool, error) {
+			return b.MaxFailures == 5, nil
+		}},
+		"max-fail-fraction": {Config{MaxFailFraction: 0.5}, func(b *Blaster) (bool, error) {
+			return b.MaxFailFraction == 0.5, nil
+		}},
+		"retry": {Config{Retry: &Retry{MaxAttempts: 4}}, func(b *Blaster) (bool, error) {
+			return b.Retry != nil && b.Retry.MaxAttempts == 4, nil
+		}},
+		"circuit-breaker": {Config{CircuitBreaker: &CircuitBreaker{Pause: "1s"}}, func(b *Blaster) (bool, error) {
+			return b.CircuitBreaker != nil && b.CircuitBreaker.Pause == "1s", nil
+		}},
+		"percentiles": {Config{Percentiles: []float64{99.99}}, func(b *Blaster) (bool, error) {
+			return len(b.Percentiles) == 1 && b.Percentiles[0] == 99.99, nil
+		}},
+		"report-file": {Config{ReportFile: "a"}, func(b *Blaster) (bool, error) {
+			return b.reportFile == "a", nil
+		}},
+		"output-format": {Config{OutputFormat: "json"}, func(b *Blaster) (bool, error) {
+			return b.OutputFormat == "json", nil
+		}},
+		"metrics-addr": {Config{MetricsAddr: ":9090"}, func(b *Blaster) (bool, error) {
+			return b.MetricsAddr == ":9090", nil
+		}},
+		"control-addr": {Config{ControlAddr: ":8081"}, func(b *Blaster) (bool, error) {
+			return b.ControlAddr == ":8081", nil
+		}},
+		"timeseries-interval": {Config{TimeseriesInterval: "500ms"}, func(b *Blaster) (bool, error) {
+			return b.TimeseriesInterval == time.Millisecond*500, nil
+		}},
+		"search": {Config{Rate: 10, Search: &Search{Duration: "2s"}}, func(b *Blaster) (bool, error) {
+			return b.Search != nil && b.Search.Duration == "2s", nil
+		}},
 	}
 	for name, test := range tests {
 		run(name, test)
 	}
 }
+
+func TestBlaster_InitialiseRateScheduleError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for _, stage := range []RateStage{
+		{Rate: 1, Duration: "a"},
+		{Rate: 1, Duration: "0s"},
+		{Rate: -1, Duration: "1s"},
+		{Rate: 1, Duration: "1s", Shape: "a"},
+	} {
+		if err := b.Initialise(ctx, Config{RateSchedule: []RateStage{stage}}); err == nil {
+			t.Fatalf("Expected error for %#v", stage)
+		}
+	}
+	if err := b.Initialise(ctx, Config{ClosedLoop: true, RateSchedule: []RateStage{{Rate: 1, Duration: "1s"}}}); err == nil {
+		t.Fatal("Expected error for rate-schedule in closed-loop mode")
+	}
+}
+
+func TestBlaster_InitialiseSearchError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for _, search := range []Search{
+		{Duration: "1s"}, // no start rate
+		{Start: 1, Duration: "a"},
+		{Start: 1, Duration: "0s"},
+		{Start: 1, Duration: "1s", Factor: 1},
+		{Start: 1, Duration: "1s", P95: "a"},
+	} {
+		search := search
+		if err := b.Initialise(ctx, Config{Search: &search}); err == nil {
+			t.Fatalf("Expected error for %#v", search)
+		}
+	}
+	if err := b.Initialise(ctx, Config{ClosedLoop: true, Search: &Search{Start: 1, Duration: "1s"}}); err == nil {
+		t.Fatal("Expected error for search in closed-loop mode")
+	}
+}
+
+func TestBlaster_InitialiseStopError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for name, c := range map[string]Config{
+		"duration invalid":  {Duration: "a"},
+		"duration zero":     {Duration: "0s"},
+		"max-requests":      {MaxRequests: -1},
+		"max-failures":      {MaxFailures: -1},
+		"max-fail-fraction": {MaxFailFraction: 2},
+	} {
+		if err := b.Initialise(ctx, c); err == nil {
+			t.Fatalf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestBlaster_InitialiseRetryError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for _, retry := range []Retry{
+		{MaxAttempts: -1},
+		{Multiplier: 0.5},
+		{Jitter: 2},
+		{Backoff: "a"},
+		{MaxBackoff: "a"},
+		{Backoff: "-1s"},
+	} {
+		retry := retry
+		if err := b.Initialise(ctx, Config{Retry: &retry}); err == nil {
+			t.Fatalf("Expected error for %#v", retry)
+		}
+	}
+}
+
+func TestBlaster_InitialiseCircuitBreakerError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for _, breaker := range []CircuitBreaker{
+		{FailFraction: 2},
+		{MinRequests: -1},
+		{Window: "a"},
+		{Pause: "a"},
+		{Window: "-1s"},
+		{Window: "5ns"},
+	} {
+		breaker := breaker
+		if err := b.Initialise(ctx, Config{CircuitBreaker: &breaker}); err == nil {
+			t.Fatalf("Expected error for %#v", breaker)
+		}
+	}
+}
+
+func TestBlaster_InitialisePercentilesError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{Percentiles: []float64{101}}); err == nil || err.Error() != "percentile 101 must be between 0 and 100" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBlaster_InitialiseDataFormatError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{DataFormat: "a"}); err == nil || err.Error() != "data-format a not found" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBlaster_InitialiseDataOrderError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{DataOrder: "a"}); err == nil || err.Error() != "data-order a not found" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, c := range []Config{{DataLoop: -2}, {DataLoop: 2, Resume: true}, {DataLoop: 2, Data: "-"}, {DataBuffer: -1}} {
+		if err := b.Initialise(ctx, c); err == nil {
+			t.Fatalf("Expected error for %+v", c)
+		}
+	}
+}
+
+func TestBlaster_InitialiseGenerateError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{Generate: []Generator{{Name: "a", Type: "b"}}}); err == nil || err.Error() != `generate a type "b" not found` {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBlaster_InitialiseCSVError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{CSV: &CSV{Comma: "ab"}}); err == nil {
+		t.Fatal("Expected error")
+	}
+	if err := b.Initialise(ctx, Config{SkipRows: -1}); err == nil || err.Error() != "skip-rows must not be negative" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBlaster_InitialiseOutputFormatError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{OutputFormat: "a"}); err == nil || err.Error() != "output-format a not found" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBlaster_InitialiseTimeseriesIntervalError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	for _, interval := range []string{"a", "0s"} {
+		if err := b.Initialise(ctx, Config{TimeseriesInterval: interval}); err == nil {
+			t.Fatalf("Expected error for %s", interval)
+		}
+	}
+}
+
+func TestBlaster_InitialiseArrivalError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{Arrival: "a"}); err == nil || err.Error() != "arrival a not found" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package blaster
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ControlHandler returns a http.Handler that serves the control API:
+//
+//	GET  /stats            returns the stats as json
+//	POST /rate?rate=N      changes the rate
+//	POST /workers?count=N  changes the number of workers
+//	POST /pause            pauses sending
+//	POST /resume           resumes sending
+//	POST /stop             ends the run gracefully
+//
+// Changes to the rate or the number of workers return 409 Conflict once the run has finished.
+func (b *Blaster) ControlHandler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	post := func(f func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			f(w, r)
+		}
+	}
+
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b.Stats())
+	})
+
+	mux.HandleFunc("/rate", post(func(w http.ResponseWriter, r *http.Request) {
+		rate, err := strconv.ParseFloat(r.FormValue("rate"), 64)
+		if err != nil || rate < 0 {
+			http.Error(w, "rate must be a non-negative number", http.StatusBadRequest)
+			return
+		}
+		if b.ClosedLoop {
+			http.Error(w, "rate can't be changed in closed-loop mode", http.StatusBadRequest)
+			return
+		}
+		if b.finished() {
+			http.Error(w, "the run has finished", http.StatusConflict)
+			return
+		}
+		b.ChangeRate(rate)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	mux.HandleFunc("/workers", post(func(w http.ResponseWriter, r *http.Request) {
+		count, err := strconv.Atoi(r.FormValue("count"))
+		if err != nil || count < 1 {
+			http.Error(w, "count must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		if b.finished() {
+			http.Error(w, "the run has finished", http.StatusConflict)
+			return
+		}
+		b.ChangeWorkers(count)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	mux.HandleFunc("/pause", post(func(w http.ResponseWriter, r *http.Request) {
+		b.SetPaused(true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	mux.HandleFunc("/resume", post(func(w http.ResponseWriter, r *http.Request) {
+		b.SetPaused(false)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	mux.HandleFunc("/stop", post(func(w http.ResponseWriter, r *http.Request) {
+		b.Stop()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	return mux
+}
+
+// listen opens the listeners of the metrics and control servers. Both are opened before either server
+// is started, so if one fails the other can be closed without leaving a server running.
+func (b *Blaster) listen() error {
+	if b.MetricsAddr != "" {
+		listener, err := net.Listen("tcp", b.MetricsAddr)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b.metricsListener = listener
+	}
+	if b.ControlAddr != "" {
+		listener, err := net.Listen("tcp", b.ControlAddr)
+		if err != nil {
+			if b.metricsListener != nil {
+				b.metricsListener.Close()
+				b.metricsListener = nil
+			}
+			return errors.WithStack(err)
+		}
+		b.controlListener = listener
+	}
+	return nil
+}
+
+// startControlServer serves the control API on the listener opened by listen.
+func (b *Blaster) startControlServer(ctx context.Context) {
+
+	listener := b.controlListener
+	if listener == nil {
+		return
+	}
+
+	server := &http.Server{Handler: b.ControlHandler()}
+
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting control server")
+		go server.Serve(listener)
+		<-b.workersFinishedChannel
+		server.Close()
+	}()
+}
@@ -0,0 +1,150 @@
+package blaster
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestControl(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.itemFinishedChannel = make(chan struct{})
+	b.ControlAddr = "127.0.0.1:0"
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	// the control server is started before the main loop, so it's listening after the first tick
+	b.mainChannel <- tickDef{}
+	<-b.itemFinishedChannel
+
+	url := "http://" + b.controlListener.Addr().String()
+
+	post := func(path string, expected int) {
+		t.Helper()
+		resp, err := http.Post(url+path, "", nil)
+		must(t, err)
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Fatalf("Unexpected status for %s: %d", path, resp.StatusCode)
+		}
+	}
+
+	stats := func() Stats {
+		t.Helper()
+		resp, err := http.Get(url + "/stats")
+		must(t, err)
+		defer resp.Body.Close()
+		var s Stats
+		must(t, json.NewDecoder(resp.Body).Decode(&s))
+		return s
+	}
+
+	if s := stats(); s.All.Summary.Success != 1 {
+		t.Fatalf("Expected 1 success, got %d", s.All.Summary.Success)
+	}
+
+	// while paused, ticks are skipped
+	post("/pause", http.StatusNoContent)
+	if s := stats(); !s.Paused {
+		t.Fatal("Expected paused")
+	}
+	b.mainChannel <- tickDef{}
+	b.mainChannel <- tickDef{}
+	if s := stats(); s.All.Summary.Started != 1 {
+		t.Fatalf("Expected 1 started while paused, got %d", s.All.Summary.Started)
+	}
+	post("/resume", http.StatusNoContent)
+	b.mainChannel <- tickDef{}
+	<-b.itemFinishedChannel
+	if s := stats(); s.Paused || s.All.Summary.Started != 2 {
+		t.Fatalf("Expected 2 started after resume, got %d", s.All.Summary.Started)
+	}
+
+	post("/rate?rate=a", http.StatusBadRequest)
+	post("/rate?rate=5", http.StatusNoContent)
+	timeout := time.After(time.Second)
+	for len(stats().Segments) != 2 {
+		select {
+		case <-timeout:
+			t.Fatal("Timeout waiting for rate change")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if s := stats(); s.Segments[1].DesiredRate != 5 {
+		t.Fatalf("Unexpected desired rate %v", s.Segments[1].DesiredRate)
+	}
+
+	resp, err := http.Get(url + "/stop")
+	must(t, err)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Unexpected status for GET /stop: %d", resp.StatusCode)
+	}
+
+	post("/stop", http.StatusNoContent)
+	must(t, <-finished)
+
+	if s := b.Stats(); s.StopReason != "stopped" {
+		t.Fatalf("Unexpected stop reason: %q", s.StopReason)
+	}
+
+	// changes after the run has finished are rejected rather than blocking
+	handler := b.ControlHandler()
+	for _, path := range []string{"/rate?rate=10", "/workers?count=2"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("Unexpected status for %s after finishing: %d", path, rec.Code)
+		}
+	}
+
+	b.Exit()
+
+}
+
+func TestControlListenError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the control address is already in use, so the control server can't listen
+	used, err := net.Listen("tcp", "127.0.0.1:0")
+	must(t, err)
+	defer used.Close()
+
+	b := New(ctx, cancel)
+	b.MetricsAddr = "127.0.0.1:0"
+	b.ControlAddr = used.Addr().String()
+	b.SetWorker(new(LoggingWorker).NewSuccess)
+
+	if err := b.start(ctx); err == nil {
+		t.Fatal("Expected error")
+	}
+	if b.metricsListener != nil {
+		t.Fatal("Metrics listener should be closed")
+	}
+
+	// no server goroutines should be left running
+	done := make(chan struct{})
+	go func() {
+		b.mainWait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Servers still running after error")
+	}
+}
@@ -0,0 +1,95 @@
+package blaster
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compression returns the compression of a data source by extension: `gzip`, `zstd` or an empty
+// string.
+func compression(name string) string {
+	switch strings.ToLower(path.Ext(strings.SplitN(name, "?", 2)[0])) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".zst", ".zstd":
+		return "zstd"
+	}
+	return ""
+}
+
+// decompress wraps r in a gzip or zstd reader if the data is compressed. Compression is detected by
+// the extension of the name, or by the magic bytes at the start of the data. If r satisfies
+// io.Closer, the returned reader closes it.
+func decompress(name string, r io.Reader) (io.Reader, error) {
+
+	if r == nil {
+		return nil, nil
+	}
+
+	br := bufio.NewReader(r)
+
+	kind := compression(name)
+	if kind == "" {
+		magic, _ := br.Peek(len(zstdMagic)) // errors are returned by the first read
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			kind = "gzip"
+		case bytes.HasPrefix(magic, zstdMagic):
+			kind = "zstd"
+		}
+	}
+
+	closer, _ := r.(io.Closer)
+
+	switch kind {
+	case "gzip":
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decompressing %s", name)
+		}
+		return &decompressReader{Reader: gr, closers: []io.Closer{gr, closer}}, nil
+	case "zstd":
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			// notest
+			return nil, errors.Wrapf(err, "decompressing %s", name)
+		}
+		return &decompressReader{Reader: zr, closers: []io.Closer{zr.IOReadCloser(), closer}}, nil
+	}
+
+	if closer != nil {
+		return &decompressReader{Reader: br, closers: []io.Closer{closer}}, nil
+	}
+	return br, nil
+}
+
+// decompressReader closes the decompressor and the underlying reader.
+type decompressReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressReader) Close() error {
+	var first error
+	for _, c := range d.closers {
+		if c == nil {
+			continue
+		}
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
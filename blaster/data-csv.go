@@ -0,0 +1,92 @@
+package blaster
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// CSV configures how csv data is read. See Config.CSV for more details.
+type CSV struct {
+	// Comma sets the field delimiter, e.g. `;`, or `\t` for tab separated data. (Default: `,`).
+	Comma string `mapstructure:"comma" json:"comma"`
+
+	// Comment sets a character that starts a comment line, e.g. `#`. Comment lines are skipped.
+	Comment string `mapstructure:"comment" json:"comment"`
+
+	// LazyQuotes allows a quote to appear in an unquoted field, and a non-doubled quote to appear in a
+	// quoted field.
+	LazyQuotes bool `mapstructure:"lazy-quotes" json:"lazy-quotes"`
+
+	// TrimLeadingSpace ignores leading white space in a field.
+	TrimLeadingSpace bool `mapstructure:"trim-leading-space" json:"trim-leading-space"`
+
+	// FieldsPerRecord sets the number of fields in each record. If 0, each record must have the same
+	// number of fields as the first record. If -1, records may have a variable number of fields, and
+	// missing fields are empty. (Default: 0).
+	FieldsPerRecord int `mapstructure:"fields-per-record" json:"fields-per-record"`
+}
+
+func (c CSV) parse() (comma, comment rune, err error) {
+	if comma, err = csvRune("comma", c.Comma); err != nil {
+		return 0, 0, err
+	}
+	if comment, err = csvRune("comment", c.Comment); err != nil {
+		return 0, 0, err
+	}
+	if comment != 0 && (comment == comma || comment == ',' && comma == 0) {
+		return 0, 0, errors.New("csv comment must be different to comma")
+	}
+	if c.FieldsPerRecord < -1 {
+		return 0, 0, errors.New("csv fields-per-record must be -1 or more")
+	}
+	return comma, comment, nil
+}
+
+// csvRune returns the single character of a csv delimiter, or 0 if it's empty.
+func csvRune(name, value string) (rune, error) {
+	if value == "" {
+		return 0, nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, errors.Errorf("csv %s %q must be a single character, and not a quote or newline", name, value)
+	}
+	return r, nil
+}
+
+// newCSVReader returns a csv reader configured by CSV.
+func (b *Blaster) newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	if b.CSV == nil {
+		return cr
+	}
+	comma, comment, _ := b.CSV.parse() // errors are reported by Start
+	if comma != 0 {
+		cr.Comma = comma
+	}
+	cr.Comment = comment
+	cr.LazyQuotes = b.CSV.LazyQuotes
+	cr.TrimLeadingSpace = b.CSV.TrimLeadingSpace
+	cr.FieldsPerRecord = b.CSV.FieldsPerRecord
+	return cr
+}
+
+// skipReader skips a number of lines at the start of the data (e.g. a preamble before the headers).
+type skipReader struct {
+	r    *bufio.Reader
+	rows int // lines still to be skipped
+}
+
+func (s *skipReader) Read(p []byte) (int, error) {
+	for s.rows > 0 {
+		if _, err := s.r.ReadString('\n'); err != nil {
+			return 0, err
+		}
+		s.rows--
+	}
+	return s.r.Read(p)
+}
@@ -0,0 +1,129 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// httpDataRetries is the number of times in a row the http data reader reconnects after the
+// connection drops. The count is reset when data is read after a reconnection.
+const httpDataRetries = 3
+
+// httpDataBackoff is the wait before the first reconnection. It doubles for each retry.
+const httpDataBackoff = time.Second
+
+// httpReader streams data from a http(s) url. If the connection drops and the server supports range
+// requests, the reader reconnects and resumes from where it stopped. Otherwise the error is
+// returned, which ends the run with a fatal error rather than silently truncating the data.
+type httpReader struct {
+	ctx     context.Context
+	client  *http.Client
+	url     string
+	body    io.ReadCloser
+	offset  int64 // bytes read so far
+	ranges  bool  // the server supports range requests
+	retries int
+	backoff time.Duration
+}
+
+func openHTTPData(ctx context.Context, client *http.Client, url string) (*httpReader, error) {
+	r := &httpReader{ctx: ctx, client: client, url: url, backoff: httpDataBackoff}
+	if err := r.connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *httpReader) connect() error {
+	req, err := http.NewRequest("GET", r.url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req = req.WithContext(r.ctx)
+	if r.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	switch {
+	case r.offset > 0 && resp.StatusCode != http.StatusPartialContent:
+		resp.Body.Close()
+		return errors.Errorf("resuming data from %s: unexpected status %s", r.url, resp.Status)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		resp.Body.Close()
+		return errors.Errorf("opening data from %s: unexpected status %s", r.url, resp.Status)
+	}
+	if r.offset > 0 {
+		// the server must resume exactly where we stopped, or items would be duplicated or skipped.
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != r.offset {
+			resp.Body.Close()
+			return errors.Errorf("resuming data from %s: Content-Range %q doesn't start at byte %d", r.url, resp.Header.Get("Content-Range"), r.offset)
+		}
+	} else {
+		r.ranges = resp.Header.Get("Accept-Ranges") == "bytes"
+	}
+	r.body = resp.Body
+	return nil
+}
+
+func (r *httpReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		// the connection dropped during the previous read
+		if err := r.reconnect(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if n > 0 {
+		// the connection is working, so a later drop gets a fresh set of retries.
+		r.retries = 0
+	}
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+	r.body.Close()
+	r.body = nil
+	if !r.ranges || r.ctx.Err() != nil {
+		return n, errors.Wrapf(err, "data connection to %s dropped after %d bytes", r.url, r.offset)
+	}
+	if n > 0 {
+		// return the data we have, and reconnect on the next read
+		return n, nil
+	}
+	return r.Read(p)
+}
+
+func (r *httpReader) reconnect() error {
+	var err error
+	for {
+		if r.retries >= httpDataRetries {
+			return errors.Wrapf(err, "data connection to %s dropped after %d bytes, and %d reconnections failed", r.url, r.offset, r.retries)
+		}
+		select {
+		case <-time.After(r.backoff << uint(r.retries)):
+		case <-r.ctx.Done():
+			return errors.WithStack(r.ctx.Err())
+		}
+		r.retries++
+		if err = r.connect(); err == nil {
+			return nil
+		}
+	}
+}
+
+// Close closes the connection.
+func (r *httpReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
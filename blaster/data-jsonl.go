@@ -0,0 +1,100 @@
+package blaster
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// mapReader is satisfied by data readers that read each item as a map of fields rather than a
+// record that is matched to Headers (e.g. json lines).
+type mapReader interface {
+	csvReader
+	ReadMap() (map[string]string, error)
+}
+
+// jsonlReader reads json lines data: each line is a json object, and each top-level key is a field.
+// String values are used as they are, null values are empty, and all other values (numbers,
+// booleans, nested objects and arrays) are compact json strings.
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024) // allow long lines
+	return &jsonlReader{scanner: scanner}
+}
+
+// ReadMap returns the fields of the next item. Blank lines are skipped.
+func (j *jsonlReader) ReadMap() (map[string]string, error) {
+	for j.scanner.Scan() {
+		j.line++
+		line := bytes.TrimSpace(j.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, errors.Wrapf(err, "json lines data line %d", j.line)
+		}
+		fields := make(map[string]string, len(raw))
+		for k, v := range raw {
+			s, err := jsonField(v)
+			if err != nil {
+				// notest
+				return nil, errors.Wrapf(err, "json lines data line %d", j.line)
+			}
+			fields[k] = s
+		}
+		return fields, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return nil, io.EOF
+}
+
+// Read returns the values of the next item, sorted by key. The main loop uses ReadMap, so this is
+// only used to satisfy csvReader.
+func (j *jsonlReader) Read() ([]string, error) {
+	fields, err := j.ReadMap()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	record := make([]string, len(keys))
+	for i, k := range keys {
+		record[i] = fields[k]
+	}
+	return record, nil
+}
+
+func jsonField(v json.RawMessage) (string, error) {
+	switch {
+	case len(v) > 0 && v[0] == '"':
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			// notest
+			return "", errors.WithStack(err)
+		}
+		return s, nil
+	case string(v) == "null":
+		return "", nil
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, v); err != nil {
+		// notest
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
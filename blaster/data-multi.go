@@ -0,0 +1,158 @@
+package blaster
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// multiReader reads several data files one after another as a single data source. If headers is
+// true, each file starts with a header record: the headers of the first file are used, and every
+// other file must have the same headers.
+type multiReader struct {
+	b       *Blaster
+	ctx     context.Context
+	files   []string
+	headers bool
+	header  []string
+	reader  csvReader // reader of the current file, or nil before the next file is opened
+	closer  io.Closer
+
+	m     sync.RWMutex
+	index int // index of the next file to open
+}
+
+func (b *Blaster) newMultiReader(ctx context.Context, files []string, headers bool) *multiReader {
+	return &multiReader{b: b, ctx: ctx, files: files, headers: headers}
+}
+
+// next closes the current file and opens the next. It returns io.EOF after the last file.
+func (m *multiReader) next() error {
+	if err := m.Close(); err != nil {
+		// notest
+		return errors.WithStack(err)
+	}
+	for {
+		m.m.Lock()
+		if m.index >= len(m.files) {
+			m.m.Unlock()
+			return io.EOF
+		}
+		name := m.files[m.index]
+		m.index++
+		m.m.Unlock()
+
+		r, err := m.b.openDataSource(m.ctx, name)
+		if err != nil {
+			return err
+		}
+		m.reader = m.b.newDataReader(r)
+		if c, ok := r.(io.Closer); ok {
+			m.closer = c
+		}
+		if !m.headers {
+			return nil
+		}
+		header, err := m.reader.Read()
+		if err == io.EOF {
+			// an empty file
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "reading headers of %s", name)
+		}
+		if m.header == nil {
+			m.header = header
+		} else if !reflect.DeepEqual(header, m.header) {
+			return errors.Errorf("headers of %s %q don't match headers of %s %q", name, header, m.files[0], m.header)
+		}
+		return nil
+	}
+}
+
+// Read returns the next record, opening the next file when the current file is finished.
+func (m *multiReader) Read() ([]string, error) {
+	for {
+		if m.reader == nil {
+			if err := m.next(); err != nil {
+				return nil, err
+			}
+		}
+		record, err := m.reader.Read()
+		if err == io.EOF {
+			m.reader = nil
+			continue
+		}
+		return record, err
+	}
+}
+
+// ReadMap returns the fields of the next item, opening the next file when the current file is
+// finished.
+func (m *multiReader) ReadMap() (map[string]string, error) {
+	for {
+		if m.reader == nil {
+			if err := m.next(); err != nil {
+				return nil, err
+			}
+		}
+		var fields map[string]string
+		var err error
+		if r, ok := m.reader.(mapReader); ok {
+			fields, err = r.ReadMap()
+		} else {
+			var record []string
+			if record, err = m.reader.Read(); err == nil {
+				fields = m.b.recordFields(record)
+			}
+		}
+		if err == io.EOF {
+			m.reader = nil
+			continue
+		}
+		return fields, err
+	}
+}
+
+// current returns the name of the file currently being read, its position and the number of files.
+func (m *multiReader) current() (name string, index, count int) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	if m.index == 0 {
+		return "", 0, len(m.files)
+	}
+	return m.files[m.index-1], m.index, len(m.files)
+}
+
+// Close closes the current file.
+func (m *multiReader) Close() error {
+	if m.closer == nil {
+		return nil
+	}
+	c := m.closer
+	m.closer = nil
+	return c.Close()
+}
+
+// dataFile returns the name of the data file currently being read, its position and the number of
+// files. The name is empty unless the data source is several files.
+func (b *Blaster) dataFile() (name string, index, count int) {
+	b.dataMutex.RLock()
+	defer b.dataMutex.RUnlock()
+	if m, ok := b.dataReader.(*multiReader); ok {
+		return m.current()
+	}
+	return "", 0, 0
+}
+
+// multipleDataFiles returns true if the data source is several files, in which case the log records
+// the file of each item.
+func (b *Blaster) multipleDataFiles() bool {
+	b.dataMutex.RLock()
+	defer b.dataMutex.RUnlock()
+	_, ok := b.dataReader.(*multiReader)
+	return ok
+}
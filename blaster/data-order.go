@@ -0,0 +1,201 @@
+package blaster
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// dataOrderDef holds the state of the data source between items: the number of completed passes
+// over the data, and the buffer of items when the data is shuffled or sampled at random. Apart from
+// passes, it is only accessed by the main loop.
+type dataOrderDef struct {
+	passes int64 // completed passes over the data, accessed atomically
+	items  int   // items read (or sent, in random mode) in the current pass
+	buffer []dataItem
+	eof    bool // the current pass has reached the end of the data (shuffle mode)
+	loaded bool // the sample has been read (random mode)
+}
+
+type dataItem struct {
+	fields map[string]string
+	file   string
+}
+
+// nextData returns the fields of the next item and the data file it was read from, in the order
+// set by DataOrder. At the end of each pass the data is re-opened, until DataLoop passes have
+// completed. io.EOF is returned after the last pass.
+func (b *Blaster) nextData() (map[string]string, string, error) {
+	var item dataItem
+	var err error
+	switch b.DataOrder {
+	case "shuffle":
+		item, err = b.nextShuffled()
+	case "random":
+		item, err = b.nextRandom()
+	default:
+		item, err = b.nextInOrder()
+	}
+	return item.fields, item.file, err
+}
+
+func (b *Blaster) nextInOrder() (dataItem, error) {
+	o := b.dataOrder
+	for {
+		item, err := b.readItem()
+		if err == io.EOF {
+			more, err := b.endPass(o.items, true)
+			o.items = 0
+			if err != nil {
+				return dataItem{}, err
+			}
+			if !more {
+				return dataItem{}, io.EOF
+			}
+			continue
+		}
+		if err != nil {
+			return dataItem{}, err
+		}
+		o.items++
+		return item, nil
+	}
+}
+
+// nextShuffled keeps DataBuffer items in memory, and returns a random one of them, replacing it
+// with the next item from the data source. Each item is returned once per pass.
+func (b *Blaster) nextShuffled() (dataItem, error) {
+	o := b.dataOrder
+	for {
+		for !o.eof && len(o.buffer) < b.DataBuffer {
+			item, err := b.readItem()
+			if err == io.EOF {
+				o.eof = true
+				break
+			}
+			if err != nil {
+				return dataItem{}, err
+			}
+			o.items++
+			o.buffer = append(o.buffer, item)
+		}
+		if len(o.buffer) > 0 {
+			i := rand.Intn(len(o.buffer))
+			item := o.buffer[i]
+			last := len(o.buffer) - 1
+			o.buffer[i] = o.buffer[last]
+			o.buffer[last] = dataItem{}
+			o.buffer = o.buffer[:last]
+			return item, nil
+		}
+		more, err := b.endPass(o.items, true)
+		o.items = 0
+		o.eof = false
+		if err != nil {
+			return dataItem{}, err
+		}
+		if !more {
+			return dataItem{}, io.EOF
+		}
+	}
+}
+
+// nextRandom reads a uniform sample of up to DataBuffer items from the data source (reservoir
+// sampling), and returns items picked at random from the sample. Each pass returns as many items as
+// the sample holds.
+func (b *Blaster) nextRandom() (dataItem, error) {
+	o := b.dataOrder
+	if !o.loaded {
+		o.loaded = true
+		var read int
+		for {
+			item, err := b.readItem()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return dataItem{}, err
+			}
+			read++
+			if len(o.buffer) < b.DataBuffer {
+				o.buffer = append(o.buffer, item)
+			} else if i := rand.Intn(read); i < b.DataBuffer {
+				o.buffer[i] = item
+			}
+		}
+	}
+	for o.items >= len(o.buffer) {
+		more, err := b.endPass(len(o.buffer), false)
+		o.items = 0
+		if err != nil {
+			// notest
+			return dataItem{}, err
+		}
+		if !more {
+			return dataItem{}, io.EOF
+		}
+	}
+	o.items++
+	return o.buffer[rand.Intn(len(o.buffer))], nil
+}
+
+// readItem reads the next item from the data source.
+func (b *Blaster) readItem() (dataItem, error) {
+	fields, err := b.readData()
+	if err != nil {
+		return dataItem{}, err
+	}
+	file, _, _ := b.dataFile()
+	return dataItem{fields: fields, file: file}, nil
+}
+
+// endPass is called at the end of each pass over the data. It returns false after the last pass,
+// and otherwise re-opens the data source for the next pass (if reopen is true). An empty data source
+// is never looped, because no items would be sent.
+func (b *Blaster) endPass(items int, reopen bool) (bool, error) {
+	passes := atomic.AddInt64(&b.dataOrder.passes, 1)
+	if items == 0 {
+		return false, nil
+	}
+	if b.DataLoop != -1 && passes >= int64(b.DataLoop) {
+		return false, nil
+	}
+	if !reopen {
+		return true, nil
+	}
+	if b.reopenData == nil {
+		return false, errors.New("data source can't be re-opened for the next pass")
+	}
+	if err := b.reopenData(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// dataPasses returns the number of completed passes over the data.
+func (b *Blaster) dataPasses() int64 {
+	return atomic.LoadInt64(&b.dataOrder.passes)
+}
+
+// setReopenData stores a function that re-opens the data source for the next pass. Data read from
+// stdin can't be re-opened.
+func (b *Blaster) setReopenData(ctx context.Context, value string, headers bool) {
+	if value == "-" {
+		b.reopenData = nil
+		return
+	}
+	b.reopenData = func() error {
+		b.dataMutex.Lock()
+		defer b.dataMutex.Unlock()
+		if b.dataCloser != nil {
+			if err := b.dataCloser.Close(); err != nil {
+				// notest
+				return errors.WithStack(err)
+			}
+		}
+		return b.openData(ctx, value, headers)
+	}
+}
@@ -0,0 +1,131 @@
+package blaster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// splitSQL splits a sql data source of the form `<driver>://<dsn>?query=<query>` into the driver
+// name, the data source name and the query, which is still url encoded. The query parameter must be
+// last, so the query may contain any characters. ok is false unless the scheme is a registered
+// database/sql driver.
+func splitSQL(value string) (driver, dsn, query string, ok bool) {
+	i := strings.Index(value, "://")
+	if i < 1 || strings.ContainsAny(value[:i], "/\n") {
+		return "", "", "", false
+	}
+	driver = value[:i]
+	switch driver {
+	case "http", "https", "gs":
+		return "", "", "", false
+	}
+	registered := false
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return "", "", "", false
+	}
+	dsn = value[i+3:]
+	// the first query parameter starts the query
+	for j := 1; j < len(dsn); j++ {
+		if (dsn[j-1] == '?' || dsn[j-1] == '&') && strings.HasPrefix(dsn[j:], "query=") {
+			query = dsn[j+len("query="):]
+			dsn = dsn[:j-1]
+			break
+		}
+	}
+	return driver, dsn, query, true
+}
+
+// sqlReader streams the rows of a sql query. Each row is a record, matched to the column names.
+type sqlReader struct {
+	db      *sql.DB
+	rows    *sql.Rows
+	columns []string
+}
+
+// openSQLData runs the query, which is url decoded as a query parameter: `+` is a space, so a literal
+// `+` or `%` must be encoded as `%2B` or `%25`.
+func openSQLData(ctx context.Context, driver, dsn, query string) (*sqlReader, error) {
+	if query == "" {
+		return nil, errors.Errorf("%s data must have a query parameter, e.g. %s://%s?query=SELECT ...", driver, driver, dsn)
+	}
+	query, err := url.QueryUnescape(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s data query (a literal %% must be encoded as %%25)", driver)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrapf(err, "running %s data query", driver)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		// notest
+		rows.Close()
+		db.Close()
+		return nil, errors.WithStack(err)
+	}
+	return &sqlReader{db: db, rows: rows, columns: columns}, nil
+}
+
+// Read returns the values of the next row. Null values are empty, and times are formatted as
+// RFC3339 with nanoseconds.
+func (s *sqlReader) Read() ([]string, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return nil, io.EOF
+	}
+	values := make([]interface{}, len(s.columns))
+	pointers := make([]interface{}, len(s.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := s.rows.Scan(pointers...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = sqlField(v)
+	}
+	return record, nil
+}
+
+func sqlField(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// Close closes the rows and the database.
+func (s *sqlReader) Close() error {
+	rowsErr := s.rows.Close()
+	if err := s.db.Close(); err != nil {
+		// notest
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(rowsErr)
+}
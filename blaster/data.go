@@ -1,17 +1,22 @@
 package blaster
 
 import (
+	"bufio"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
 
 	"context"
 	"strings"
 
 	"io"
-
-	"encoding/csv"
+	"io/ioutil"
+	"net/http"
 
 	"cloud.google.com/go/storage"
 	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
 )
 
 // ReadHeaders reads one row from the data source and stores that in Headers
@@ -24,15 +29,15 @@ func (b *Blaster) ReadHeaders() error {
 	return nil
 }
 
-// SetData sets the CSV data source. If the provided io.Reader also satisfies io.Closer it will be
-// closed on exit.
+// SetData sets the data source. The data is read as csv (configured by CSV) unless DataFormat is
+// `jsonl`. If the provided io.Reader also satisfies io.Closer it will be closed on exit.
 func (b *Blaster) SetData(r io.Reader) {
 	if r == nil {
 		b.dataReader = nil
 		b.dataCloser = nil
 		return
 	}
-	b.dataReader = csv.NewReader(r)
+	b.dataReader = b.newDataReader(r)
 	if c, ok := r.(io.Closer); ok {
 		b.dataCloser = c
 	} else {
@@ -44,29 +49,69 @@ func (b *Blaster) openData(ctx context.Context, value string, headers bool) erro
 	if value == "" {
 		return nil
 	}
-	var r io.Reader
+
+	b.setReopenData(ctx, value, headers)
+
+	if driver, dsn, query, ok := splitSQL(value); ok {
+		if b.SkipRows > 0 || b.CSV != nil || b.DataFormat != "" {
+			return errors.Errorf("skip-rows, data-format and csv can't be used with %s data", driver)
+		}
+		r, err := openSQLData(ctx, driver, dsn, query)
+		if err != nil {
+			return err
+		}
+		b.dataReader = r
+		b.dataCloser = r
+		if headers {
+			// the column names are used as the headers
+			b.Headers = r.columns
+		}
+		return nil
+	}
+
+	if b.DataFormat == "" {
+		b.DataFormat = dataFormat(value)
+	}
+
 	if strings.Contains(value, "\n") {
-		r = strings.NewReader(value)
-	} else if strings.HasPrefix(value, "gs://") {
-		name := strings.TrimPrefix(value, "gs://")
-		bucket := name[:strings.Index(name, "/")]
-		handle := name[strings.Index(name, "/")+1:]
-		gr, err := b.gcs.open(ctx, bucket, handle)
+		r, err := decompress("", strings.NewReader(value))
 		if err != nil {
-			return errors.WithStack(err)
+			return err
 		}
-		r = gr
+		b.SetData(r)
 	} else {
-		fr, err := os.Open(value)
+		files, err := b.listData(ctx, value)
 		if err != nil {
-			return errors.WithStack(err)
+			return err
+		}
+		if files == nil {
+			// a single data source
+			r, err := b.openDataSource(ctx, value)
+			if err != nil {
+				return err
+			}
+			b.SetData(r)
+		} else {
+			if b.DataFormat == "" {
+				b.DataFormat = dataFormat(files[0])
+			}
+			// json lines data has no header record
+			m := b.newMultiReader(ctx, files, headers && b.DataFormat != "jsonl")
+			b.dataReader = m
+			b.dataCloser = m
+			if m.headers {
+				// the headers are read from the first file, and checked against each subsequent file.
+				if err := m.next(); err != nil {
+					return err
+				}
+				b.Headers = m.header
+			}
+			return nil
 		}
-		r = fr
 	}
 
-	b.SetData(r)
-
-	if headers {
+	// json lines data has no header record
+	if headers && b.DataFormat != "jsonl" {
 		if err := b.ReadHeaders(); err != nil {
 			return err
 		}
@@ -75,8 +120,162 @@ func (b *Blaster) openData(ctx context.Context, value string, headers bool) erro
 
 }
 
+// dataFormat chooses the data format by extension. The extension of compressed data is ignored,
+// e.g. `data.jsonl.gz` is json lines.
+func dataFormat(value string) string {
+	name := strings.SplitN(value, "?", 2)[0]
+	if compression(name) != "" {
+		name = strings.TrimSuffix(name, path.Ext(name))
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	}
+	return ""
+}
+
+// openDataSource opens a single data source: `-` for stdin, a http(s) url, a GCS object or a local
+// file. Compressed data is decompressed.
+func (b *Blaster) openDataSource(ctx context.Context, value string) (io.Reader, error) {
+	r, err := b.openRawDataSource(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(value, r)
+}
+
+func (b *Blaster) openRawDataSource(ctx context.Context, value string) (io.Reader, error) {
+	switch {
+	case value == "-":
+		// don't close stdin on exit
+		b.dataStdin = true
+		return ioutil.NopCloser(b.stdin), nil
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return openHTTPData(ctx, http.DefaultClient, value)
+	case strings.HasPrefix(value, "gs://"):
+		bucket, handle := splitGcs(value)
+		gr, err := b.gcs.open(ctx, bucket, handle)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return gr, nil
+	default:
+		fr, err := os.Open(value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return fr, nil
+	}
+}
+
+// listData returns the files matched by a glob pattern, a local directory or a GCS prefix (ending in
+// a slash), sorted by name. If the value is a single data source, nil is returned.
+// pathExists returns true if the file or directory exists.
+func pathExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func (b *Blaster) listData(ctx context.Context, value string) ([]string, error) {
+	var files []string
+	switch {
+	case value == "-" || strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return nil, nil
+	case strings.HasPrefix(value, "gs://"):
+		if !strings.HasSuffix(value, "/") {
+			return nil, nil
+		}
+		bucket, prefix := splitGcs(value)
+		names, err := b.gcs.list(ctx, bucket, prefix)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, name := range names {
+			files = append(files, "gs://"+bucket+"/"+name)
+		}
+	case strings.ContainsAny(value, "*?[") && !pathExists(value):
+		// a file that exists is read as is, even if its name contains glob characters
+		matches, err := filepath.Glob(value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, match := range matches {
+			if fi, err := os.Stat(match); err == nil && !fi.IsDir() {
+				files = append(files, match)
+			}
+		}
+	default:
+		fi, err := os.Stat(value)
+		if err != nil || !fi.IsDir() {
+			// a single file (errors are returned when it's opened)
+			return nil, nil
+		}
+		infos, err := ioutil.ReadDir(value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, fi := range infos {
+			if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+				continue
+			}
+			files = append(files, filepath.Join(value, fi.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("no data files found in %s", value)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func splitGcs(value string) (bucket, handle string) {
+	name := strings.TrimPrefix(value, "gs://")
+	if i := strings.Index(name, "/"); i > -1 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// readData reads the fields of the next item from the data source.
+func (b *Blaster) readData() (map[string]string, error) {
+	if r, ok := b.dataReader.(mapReader); ok {
+		return r.ReadMap()
+	}
+	record, err := b.dataReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return b.recordFields(record), nil
+}
+
+// recordFields matches a csv record to the headers. Missing fields are empty.
+func (b *Blaster) recordFields(record []string) map[string]string {
+	fields := make(map[string]string, len(b.Headers))
+	for i, k := range b.Headers {
+		if i < len(record) {
+			fields[k] = record[i]
+		} else {
+			fields[k] = ""
+		}
+	}
+	return fields
+}
+
+// newDataReader returns a csv or json lines reader, depending on DataFormat. The first SkipRows lines
+// are skipped.
+func (b *Blaster) newDataReader(r io.Reader) csvReader {
+	if b.SkipRows > 0 {
+		r = &skipReader{r: bufio.NewReader(r), rows: b.SkipRows}
+	}
+	if b.DataFormat == "jsonl" {
+		return newJSONLReader(r)
+	}
+	return b.newCSVReader(r)
+}
+
 type opener interface {
 	open(context.Context, string, string) (io.Reader, error)
+	list(context.Context, string, string) ([]string, error)
 }
 
 type googleCloudOpener struct{}
@@ -93,3 +292,28 @@ func (googleCloudOpener) open(ctx context.Context, bucket, handle string) (io.Re
 	}
 	return gr, nil
 }
+
+func (googleCloudOpener) list(ctx context.Context, bucket, prefix string) ([]string, error) {
+	// notest
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var names []string
+	it := client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if strings.HasSuffix(attrs.Name, "/") {
+			// skip directory placeholders
+			continue
+		}
+		names = append(names, attrs.Name)
+	}
+	return names, nil
+}
@@ -1,9 +1,22 @@
 package blaster
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestOpenGcs(t *testing.T) {
@@ -31,3 +44,559 @@ func (l *loggingOpener) open(ctx context.Context, bucket, handle string) (io.Rea
 	l.handle = handle
 	return nil, nil
 }
+
+func (l *loggingOpener) list(ctx context.Context, bucket, prefix string) ([]string, error) {
+	l.bucket = bucket
+	l.handle = prefix
+	return nil, nil
+}
+
+func TestJSONLReader(t *testing.T) {
+	r := newJSONLReader(strings.NewReader(`{"a":"x","b":1.50,"c":true,"d":null,"e":{"f": [1, "g"]}}
+
+{"a":"y\nz"}
+`))
+	fields, err := r.ReadMap()
+	must(t, err)
+	expected := map[string]string{"a": "x", "b": "1.50", "c": "true", "d": "", "e": `{"f":[1,"g"]}`}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("Unexpected fields: %#v", fields)
+	}
+	record, err := r.Read()
+	must(t, err)
+	if !reflect.DeepEqual(record, []string{"y\nz"}) {
+		t.Fatalf("Unexpected record: %#v", record)
+	}
+	if _, err := r.ReadMap(); err != io.EOF {
+		t.Fatalf("Expected EOF, got %v", err)
+	}
+
+	r = newJSONLReader(strings.NewReader("{\"a\":\"x\"}\n[1]\n"))
+	_, err = r.ReadMap()
+	must(t, err)
+	if _, err := r.ReadMap(); err == nil || !strings.HasPrefix(err.Error(), "json lines data line 2") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestOpenDataJSONL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	f, _ := ioutil.TempFile("", "*.jsonl")
+	f.WriteString(`{"a":"1","b":"2"}`)
+	f.Close()
+	defer os.Remove(f.Name())
+
+	b := New(ctx, cancel)
+	must(t, b.openData(ctx, f.Name(), true))
+	if b.DataFormat != "jsonl" || len(b.Headers) != 0 {
+		t.Fatalf("Unexpected format %q and headers %v", b.DataFormat, b.Headers)
+	}
+	jsonl, err := b.readData()
+	must(t, err)
+
+	// the same data in csv format gives the same fields, so resume hashes are unchanged.
+	b = New(ctx, cancel)
+	must(t, b.openData(ctx, "a,b\n1,2", true))
+	csv, err := b.readData()
+	must(t, err)
+	if !reflect.DeepEqual(jsonl, csv) {
+		t.Fatalf("Unexpected fields %v, %v", jsonl, csv)
+	}
+
+	// the format may be set explicitly
+	b = New(ctx, cancel)
+	b.DataFormat = "jsonl"
+	must(t, b.openData(ctx, "{\"c\":3}\n", true))
+	fields, err := b.readData()
+	must(t, err)
+	if !reflect.DeepEqual(fields, map[string]string{"c": "3"}) {
+		t.Fatalf("Unexpected fields %v", fields)
+	}
+}
+
+func TestOpenDataStdin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.stdin = strings.NewReader("a,b\n1,2")
+	must(t, b.openData(ctx, "-", true))
+	if !b.dataStdin || !reflect.DeepEqual(b.Headers, []string{"a", "b"}) {
+		t.Fatalf("Unexpected stdin data: %v, %v", b.dataStdin, b.Headers)
+	}
+}
+
+// dropServer serves data, dropping the connection after the first half of the body. If ranges is
+// true, range requests are supported.
+func dropServer(t *testing.T, data string, ranges bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			var start int
+			fmt.Sscanf(rng, "bytes=%d-", &start)
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)-start))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, data[start:])
+			return
+		}
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		io.WriteString(w, data[:len(data)/2])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		must(t, err)
+		conn.Close()
+	}))
+}
+
+// chunkServer serves data with range requests, dropping the connection after each chunk. If
+// restart is true, resumed requests start from the beginning of the data.
+func chunkServer(t *testing.T, data string, chunk int, restart bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Ranges", "bytes")
+		var start int
+		if rng := r.Header.Get("Range"); rng != "" {
+			fmt.Sscanf(rng, "bytes=%d-", &start)
+			if restart {
+				start = 0
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		}
+		end := start + chunk
+		if end >= len(data) {
+			io.WriteString(w, data[start:])
+			return
+		}
+		io.WriteString(w, data[start:end])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		must(t, err)
+		conn.Close()
+	}))
+}
+
+func TestOpenDataHTTP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	data := "a,b\n1,2\n3,4\n5,6\n"
+
+	read := func(url string) (string, error) {
+		r, err := openHTTPData(ctx, http.DefaultClient, url)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		r.backoff = time.Millisecond
+		b, err := ioutil.ReadAll(r)
+		return string(b), err
+	}
+
+	server := dropServer(t, data, true)
+	defer server.Close()
+	out, err := read(server.URL + "/data.csv")
+	must(t, err)
+	if out != data {
+		t.Fatalf("Unexpected data: %q", out)
+	}
+
+	if _, err := read(server.URL + "/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	noRanges := dropServer(t, data, false)
+	defer noRanges.Close()
+	if _, err := read(noRanges.URL); err == nil || !strings.Contains(err.Error(), "dropped after") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the connection drops more times than httpDataRetries, but data is read after each reconnection
+	chunks := chunkServer(t, data, 3, false)
+	defer chunks.Close()
+	out, err = read(chunks.URL)
+	must(t, err)
+	if out != data {
+		t.Fatalf("Unexpected data: %q", out)
+	}
+
+	// the server doesn't resume where the connection dropped
+	badRange := chunkServer(t, data, 3, true)
+	defer badRange.Close()
+	if _, err := read(badRange.URL); err == nil || !strings.Contains(err.Error(), "doesn't start at byte 3") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b := New(ctx, cancel)
+	must(t, b.openData(ctx, server.URL+"/data.jsonl?a=b", true))
+	if b.DataFormat != "jsonl" {
+		t.Fatalf("Unexpected format: %q", b.DataFormat)
+	}
+	b.Exit()
+}
+
+// mapOpener serves GCS objects from a map of names to contents.
+type mapOpener map[string]string
+
+func (m mapOpener) open(ctx context.Context, bucket, handle string) (io.Reader, error) {
+	return strings.NewReader(m[bucket+"/"+handle]), nil
+}
+
+func (m mapOpener) list(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var names []string
+	for name := range m {
+		if strings.HasPrefix(name, bucket+"/"+prefix) {
+			names = append(names, strings.TrimPrefix(name, bucket+"/"))
+		}
+	}
+	return names, nil
+}
+
+// readFiles reads all items, and returns the file and the "a" and "b" fields of each.
+func readFiles(t *testing.T, b *Blaster) (values []string) {
+	t.Helper()
+	for {
+		fields, err := b.readData()
+		if err == io.EOF {
+			return
+		}
+		must(t, err)
+		file, _, _ := b.dataFile()
+		values = append(values, file+":"+fields["a"]+fields["b"])
+	}
+}
+
+func TestOpenDataMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	for name, contents := range map[string]string{
+		"1.csv":   "a,b\n1,2\n3,4\n",
+		"2.csv":   "a,b\n5,6\n",
+		"3.csv":   "",
+		".hidden": "x",
+	} {
+		must(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0666))
+	}
+	file := func(name string) string { return filepath.Join(dir, name) }
+
+	for _, value := range []string{dir, file("*.csv")} {
+		b := New(ctx, cancel)
+		must(t, b.openData(ctx, value, true))
+		if !reflect.DeepEqual(b.Headers, []string{"a", "b"}) {
+			t.Fatalf("Unexpected headers: %v", b.Headers)
+		}
+		expected := []string{file("1.csv") + ":12", file("1.csv") + ":34", file("2.csv") + ":56"}
+		if values := readFiles(t, b); !reflect.DeepEqual(values, expected) {
+			t.Fatalf("Unexpected values for %s: %v", value, values)
+		}
+		if name, index, count := b.dataFile(); name != file("3.csv") || index != 3 || count != 3 {
+			t.Fatalf("Unexpected data file: %s, %d, %d", name, index, count)
+		}
+		b.Exit()
+	}
+
+	// headers may be set explicitly, in which case no header record is expected
+	b := New(ctx, cancel)
+	b.Headers = []string{"b", "a"}
+	must(t, b.openData(ctx, file("2*"), false))
+	if values := readFiles(t, b); !reflect.DeepEqual(values, []string{file("2.csv") + ":ba", file("2.csv") + ":65"}) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+
+	must(t, ioutil.WriteFile(file("4.csv"), []byte("a,c\n7,8\n"), 0666))
+	b = New(ctx, cancel)
+	must(t, b.openData(ctx, dir, true))
+	for err == nil {
+		_, err = b.readData()
+	}
+	if !strings.HasPrefix(err.Error(), "headers of "+file("4.csv")) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := New(ctx, cancel).openData(ctx, file("*.tsv"), true); err == nil || !strings.HasPrefix(err.Error(), "no data files found") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// a file whose name contains glob characters is read rather than matched
+	must(t, ioutil.WriteFile(file("data[1].csv"), []byte("a,b\n9,0\n"), 0666))
+	b = New(ctx, cancel)
+	must(t, b.openData(ctx, file("data[1].csv"), true))
+	fields, err := b.readData()
+	must(t, err)
+	if !reflect.DeepEqual(fields, map[string]string{"a": "9", "b": "0"}) {
+		t.Fatalf("Unexpected fields: %v", fields)
+	}
+	b.Exit()
+
+	b = New(ctx, cancel)
+	b.gcs = mapOpener{"bucket/p/2.jsonl": `{"a":"3"}`, "bucket/p/1.jsonl": `{"a":"1"}` + "\n" + `{"a":"2"}`, "bucket/q/1.jsonl": `{"a":"4"}`}
+	must(t, b.openData(ctx, "gs://bucket/p/", true))
+	if b.DataFormat != "jsonl" {
+		t.Fatalf("Unexpected format: %q", b.DataFormat)
+	}
+	if values := readFiles(t, b); !reflect.DeepEqual(values, []string{"gs://bucket/p/1.jsonl:1", "gs://bucket/p/1.jsonl:2", "gs://bucket/p/2.jsonl:3"}) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+}
+
+func TestMultipleDataFilesLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	must(t, ioutil.WriteFile(filepath.Join(dir, "1.csv"), []byte("a\n1\n"), 0666))
+	must(t, ioutil.WriteFile(filepath.Join(dir, "2.csv"), []byte("a\n2\n"), 0666))
+
+	b := New(ctx, cancel)
+	b.Rate = 100
+	b.Workers = 1
+	b.LogData = []string{"a"}
+	must(t, b.openData(ctx, dir, true))
+
+	buf := new(bytes.Buffer)
+	b.SetLog(buf)
+	must(t, b.WriteLogHeaders())
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+	b.Exit()
+
+	records, err := csv.NewReader(buf).ReadAll()
+	must(t, err)
+	if len(records) != 3 || !reflect.DeepEqual(records[0], []string{"hash", "result", "file", "a"}) {
+		t.Fatalf("Unexpected log: %v", records)
+	}
+	for i, r := range records[1:] {
+		if r[2] != filepath.Join(dir, fmt.Sprintf("%d.csv", i+1)) || r[3] != fmt.Sprint(i+1) {
+			t.Fatalf("Unexpected log record: %v", r)
+		}
+	}
+	if !regexp.MustCompile(`Data file:\s+` + regexp.QuoteMeta(filepath.Join(dir, "2.csv")+" (2 of 2)")).MatchString(stats.String()) {
+		t.Fatalf("Unexpected stats:\n%s", stats)
+	}
+}
+
+// runLogged runs b with a single worker, and returns the value of field a of each item in the log.
+func runLogged(t *testing.T, ctx context.Context, b *Blaster) ([]string, Stats) {
+	t.Helper()
+	return runLoggedTo(t, ctx, b, new(bytes.Buffer))
+}
+
+// runLoggedTo runs b in the same way as runLogged, writing the log to buf.
+func runLoggedTo(t *testing.T, ctx context.Context, b *Blaster, buf *bytes.Buffer) ([]string, Stats) {
+	t.Helper()
+	b.Rate = 1000
+	b.Workers = 1
+	b.LogData = []string{"a"}
+
+	b.SetLog(buf)
+	must(t, b.WriteLogHeaders())
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+	b.Exit()
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	must(t, err)
+	var values []string
+	for _, r := range records[1:] {
+		values = append(values, r[len(r)-1])
+	}
+	return values, stats
+}
+
+func TestDataLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.DataLoop = 3
+	must(t, b.openData(ctx, "a\n1\n2\n3\n", true))
+
+	values, stats := runLogged(t, ctx, b)
+	if expected := []string{"1", "2", "3", "1", "2", "3", "1", "2", "3"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+	if stats.DataPasses != 3 || !regexp.MustCompile(`Data passes:\s+3 of 3 completed`).MatchString(stats.String()) {
+		t.Fatalf("Unexpected stats:\n%s", stats)
+	}
+}
+
+func TestDataLoopMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	must(t, ioutil.WriteFile(filepath.Join(dir, "1.csv"), []byte("a\n1\n"), 0666))
+	must(t, ioutil.WriteFile(filepath.Join(dir, "2.csv"), []byte("a\n2\n"), 0666))
+
+	b := New(ctx, cancel)
+	b.DataLoop = 2
+	must(t, b.openData(ctx, dir, true))
+
+	values, _ := runLogged(t, ctx, b)
+	if expected := []string{"1", "2", "1", "2"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+}
+
+func TestDataLoopForever(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.DataLoop = -1
+	b.MaxRequests = 10
+	must(t, b.openData(ctx, "a\n1\n2\n3\n", true))
+
+	values, stats := runLogged(t, ctx, b)
+	if len(values) != 10 || values[9] != "1" {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+	if stats.DataPasses != 3 || !regexp.MustCompile(`Data passes:\s+3 completed \(looping until stopped\)`).MatchString(stats.String()) {
+		t.Fatalf("Unexpected stats:\n%s", stats)
+	}
+}
+
+func TestDataLoopStdin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.stdin = strings.NewReader("a\n1\n")
+	b.DataLoop = 2
+	b.Workers = 1
+	must(t, b.openData(ctx, "-", true))
+	b.SetWorker(new(LoggingWorker).NewSuccess)
+
+	defer b.Exit()
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "stdin") {
+			t.Fatalf("Unexpected panic: %v", r)
+		}
+	}()
+	b.Start(ctx)
+}
+
+func TestDataOrderShuffle(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.DataOrder = "shuffle"
+	b.DataBuffer = 10
+	b.DataLoop = 2
+	var data, sorted []string
+	for i := 0; i < 50; i++ {
+		data = append(data, fmt.Sprint(i))
+		sorted = append(sorted, fmt.Sprint(i), fmt.Sprint(i))
+	}
+	must(t, b.openData(ctx, "a\n"+strings.Join(data, "\n")+"\n", true))
+
+	values, stats := runLogged(t, ctx, b)
+	if reflect.DeepEqual(values[:50], data) || reflect.DeepEqual(values[50:], data) {
+		t.Fatalf("Values not shuffled: %v", values)
+	}
+	shuffled := append([]string(nil), values...)
+	sort.Strings(shuffled)
+	sort.Strings(sorted)
+	if !reflect.DeepEqual(shuffled, sorted) {
+		t.Fatalf("Each value should be sent once per pass: %v", values)
+	}
+	if stats.DataPasses != 2 {
+		t.Fatalf("Unexpected passes: %d", stats.DataPasses)
+	}
+}
+
+func TestDataOrderRandom(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.stdin = strings.NewReader("a\n1\n2\n3\n4\n5\n")
+	b.DataOrder = "random"
+	b.DataBuffer = 3
+	b.DataLoop = 2
+	// random samples aren't re-opened, so they can be read from stdin
+	must(t, b.openData(ctx, "-", true))
+
+	values, stats := runLogged(t, ctx, b)
+	if len(values) != 6 {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+	sample := map[string]bool{}
+	for _, v := range values {
+		if v < "1" || v > "5" {
+			t.Fatalf("Unexpected value: %s", v)
+		}
+		sample[v] = true
+	}
+	if len(sample) > 3 {
+		t.Fatalf("Values should be sampled from 3 items: %v", values)
+	}
+	if stats.DataPasses != 2 {
+		t.Fatalf("Unexpected passes: %d", stats.DataPasses)
+	}
+}
+
+func TestOpenDataCSV(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.CSV = &CSV{Comma: "\t", Comment: "#", LazyQuotes: true, TrimLeadingSpace: true, FieldsPerRecord: -1}
+	b.SkipRows = 2
+	data := "exported 2026-10-18\n\"preamble\twith \"bad\" quotes\na\tb\tc\n# comment\n1\t  2\t3\nx\"y\"\t\"z\n"
+	must(t, b.openData(ctx, data, true))
+	if !reflect.DeepEqual(b.Headers, []string{"a", "b", "c"}) {
+		t.Fatalf("Unexpected headers: %v", b.Headers)
+	}
+	var items []map[string]string
+	for {
+		fields, err := b.readData()
+		if err == io.EOF {
+			break
+		}
+		must(t, err)
+		items = append(items, fields)
+	}
+	expected := []map[string]string{{"a": "1", "b": "2", "c": "3"}, {"a": `x"y"`, "b": "z\n", "c": ""}}
+	if !reflect.DeepEqual(items, expected) {
+		t.Fatalf("Unexpected items: %q", items)
+	}
+}
+
+func TestOpenDataSkipRowsMultiple(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	must(t, ioutil.WriteFile(filepath.Join(dir, "1.csv"), []byte("report 1\na;b\n1;2\n"), 0666))
+	must(t, ioutil.WriteFile(filepath.Join(dir, "2.csv"), []byte("report 2\na;b\n3;4\n"), 0666))
+
+	b := New(ctx, cancel)
+	b.CSV = &CSV{Comma: ";"}
+	b.SkipRows = 1
+	must(t, b.openData(ctx, dir, true))
+	expected := []string{filepath.Join(dir, "1.csv") + ":12", filepath.Join(dir, "2.csv") + ":34"}
+	if values := readFiles(t, b); !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+}
+
+func TestCSVError(t *testing.T) {
+	for _, c := range []CSV{{Comma: ";;"}, {Comma: `"`}, {Comma: "\n"}, {Comment: ","}, {Comma: "#", Comment: "#"}, {FieldsPerRecord: -2}} {
+		if _, _, err := c.parse(); err == nil {
+			t.Fatalf("Expected error for %+v", c)
+		}
+	}
+}
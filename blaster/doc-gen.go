@@ -1,87 +1,325 @@
 package blaster
 
 var doc = map[string]string{
-	"Blaster":                    "Blaster provides the back-end blast: a simple tool for API load testing and batch jobs. Use the New function to create a Blaster with default values.",
-	"Blaster.ChangeRate":         "ChangeRate changes the sending rate during execution.",
-	"Blaster.Command":            "Command processes command line flags, loads the config and starts the blast run.",
-	"Blaster.Exit":               "Exit cancels any goroutines that are still processing, and closes all files.",
-	"Blaster.Headers":            "Headers sets the data headers. See Config.Headers for more details.",
-	"Blaster.Initialise":         "Initialise configures the Blaster with config options in a provided Config",
-	"Blaster.LoadConfig":         "LoadConfig parses command line flags and loads a config file from disk. A Config is returned which may be used with the Initialise method to complete configuration.",
-	"Blaster.LoadLogs":           "LoadLogs loads the logs from a previous run, and stores successfully completed items so they can be skipped in the current run.",
-	"Blaster.LogData":            "LogData sets the data fields to be logged. See Config.LogData for more details.",
-	"Blaster.LogOutput":          "LogOutput sets the output fields to be logged. See Config.LogOutput for more details.",
-	"Blaster.PayloadVariants":    "PayloadVariants sets the payload variants. See Config.PayloadVariants for more details.",
-	"Blaster.PrintStatus":        "PrintStatus prints the status message to the output writer",
-	"Blaster.Quiet":              "Quiet disables the status output.",
-	"Blaster.Rate":               "Rate sets the initial sending rate. Do not change this during a run - use the ChangeRate method instead. See Config.Resume for more details.",
-	"Blaster.ReadHeaders":        "ReadHeaders reads one row from the data source and stores that in Headers",
-	"Blaster.RegisterWorkerType": "RegisterWorkerType registers a new worker function that can be referenced in config file by the worker-type string field.",
-	"Blaster.Resume":             "Resume sets the resume option. See Config.Resume for more details.",
-	"Blaster.SetData":            "SetData sets the CSV data source. If the provided io.Reader also satisfies io.Closer it will be\nclosed on exit.",
-	"Blaster.SetInput":           "SetInput sets the rate adjustment reader, and allows testing rate adjustments. The Command method sets this to os.Stdin for interactive command line usage.",
-	"Blaster.SetLog":             "SetLog sets the log output. If the provided writer also satisfies io.Closer, it will be closed on exit.",
-	"Blaster.SetOutput":          "SetOutput sets the summary output writer, and allows the output to be redirected. The Command method sets this to os.Stdout for command line usage.",
-	"Blaster.SetPayloadTemplate": "SetPayloadTemplate sets the payload template. See Config.PayloadTemplate for more details.",
-	"Blaster.SetTimeout":         "SetTimeout sets the timeout. See Config.Timeout for more details.",
-	"Blaster.SetWorker":          "SetWorker sets the worker creation function. See httpworker for a simple example.",
-	"Blaster.SetWorkerTemplate":  "SetWorkerTemplate sets the worker template. See Config.WorkerTemplate for more details.",
-	"Blaster.Start":              "Start starts the blast run without processing any config.",
-	"Blaster.WorkerVariants":     "WorkerVariants sets the worker variants. See Config.WorkerVariants for more details.",
-	"Blaster.Workers":            "Workers sets the number of workers. See Config.Workers for more details.",
-	"Blaster.WriteLogHeaders":    "WriteLogHeaders writes the log headers to the log writer.",
-	"Config":                     "Config provides all the standard config options. Use the Initialise method to configure with a provided Config.",
-	"Config.Data":                "Data sets the the data file to load. If none is specified, the worker will be called repeatedly until interrupted (useful for load testing). Load a local file or stream directly from a GCS bucket with `gs://{bucket}/{filename}.csv`. Data should be in csv format, and if `headers` is not specified the first record will be used as the headers. If a newline character is found, this string is read as the data.",
-	"Config.Headers":             "Headers sets the data file headers. If omitted, the first record of the csv data source is used. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.Log":                 "Log sets the filename of the log file to create / append to.",
-	"Config.LogData":             "LogData sets an array of data fields to include in the output log. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.LogOutput":           "LogOutput sets an array of worker response fields to include in the output log. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.PayloadTemplate":     "PayloadTemplate sets the template that is rendered and passed to the worker `Send` method. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.PayloadVariants":     "PayloadVariants sets an array of maps that will cause each data item to be repeated with the provided data. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.Quiet":               "Quiet instructs the tool to prevent interactive features. No summary is printed during operation and the rate cannot be changed interactively.",
-	"Config.Rate":                "Rate sets the initial rate in requests per second. Simply enter a new rate during execution to adjust this. (Default: 10 requests / second).",
-	"Config.Resume":              "Resume instructs the tool to load the log file and skip previously successful items. Failed items will be retried.",
-	"Config.Timeout":             "Timeout sets the deadline in the context passed to the worker. Workers must respect this the context cancellation. We exit with an error if any worker is processing for timeout + 1 second. (Default: 1 second).",
-	"Config.WorkerTemplate":      "WorkerTemplate sets a template to render and pass to the worker `Start` or `Stop` methods if the worker satisfies the `Starter` or `Stopper` interfaces. Use with `worker-variants` to configure several workers differently to spread load. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.WorkerType":          "WorkerType sets the selected worker type. Register new worker types with the `RegisterWorkerType` method.",
-	"Config.WorkerVariants":      "WorkerVariants sets an array of maps that will cause each worker to be initialised with different data. When setting this by command line flag or environment variable, use a json encoded string.",
-	"Config.Workers":             "Workers sets the number of concurrent workers. (Default: 10 workers).",
-	"DummyCloser":                "",
-	"ExampleWorker":              "ExampleWorker facilitates code examples by satisfying the Worker, Starter and Stopper interfaces with provided functions.",
-	"ExampleWorker.Send":         "Send satisfies the Worker interface.",
-	"ExampleWorker.Start":        "Start satisfies the Starter interface.",
-	"ExampleWorker.Stop":         "Stop satisfies the Stopper interface.",
-	"LoggingReadWriteCloser":     "",
-	"LoggingWorker":              "",
-	"LoggingWriter":              "",
-	"New":                        "New creates a new Blaster with defaults.",
-	"Segment":                    "",
-	"Starter":                    "Starter and Stopper are interfaces a worker can optionally satisfy to provide initialization or finalization logic. See `httpworker` and `dummyworker` for simple examples.",
-	"Stats":                      "",
-	"Status":                     "",
-	"Stopper":                    "Stopper is an interface a worker can optionally satisfy to provide finalization logic.",
-	"ThreadSafeBuffer":           "",
-	"Total":                      "",
-	"Worker":                     "Worker is an interface that allows blast to easily be extended to support any protocol. See `main.go` for an example of how to build a command with your custom worker type.",
-	"csvReader":                  "",
-	"csvWriteFlusher":            "",
-	"debug":                      "Set debug to true to print the number of active goroutines with every status.",
-	"doc_go":                     "Package blaster provides the back-end for blast - a tool for load testing and sending api requests in bulk.\n\n Blast\n =====\n\n * Blast makes API requests at a fixed rate.\n * The number of concurrent workers is configurable.\n * The rate may be changed interactively during execution.\n * Blast is protocol agnostic, and adding a new worker type is trivial.\n * For load testing: random data can be added to API requests.\n * For batch jobs: CSV data can be loaded from local file or GCS bucket, and successful items from previous runs are skipped.\n\n Installation\n ============\n ## Mac\n ```\n brew tap dave/blast\n brew install blast\n ```\n\n ## Linux\n See the [releases page](https://github.com/dave/blast/releases)\n\n ## From source\n ```\n go get -u github.com/dave/blast\n ```\n\n Examples\n ========\n Using the dummy worker to send at 20,000 requests per second (the dummy worker returns after a random wait, and occasionally returns errors):\n ```\n blast --rate=20000 --workers=1000 --worker-type=\"dummy\" --worker-template='{\"min\":25,\"max\":50}'\n ```\n\n Using the http worker to request Google's homepage at one request per second (warning: this is making real http requests - don't turn the rate up!):\n ```\n blast --rate=1 --worker-type=\"http\" --payload-template='{\"method\":\"GET\",\"url\":\"http://www.google.com/\"}'\n ```\n\n Status\n ======\n\n Blast prints a summary every ten seconds. While blast is running, you can hit enter for an updated\n summary, or enter a number to change the sending rate. Each time you change the rate a new column\n of metrics is created. If the worker returns a field named `status` in it's response, the values\n are summarised as rows.\n\n Here's an example of the output:\n\n ```\n Metrics\n =======\n Concurrency:      1999 / 2000 workers in use\n\n Desired rate:     (all)        10000        1000         100\n Actual rate:      2112         5354         989          100\n Avg concurrency:  1733         1976         367          37\n Duration:         00:40        00:12        00:14        00:12\n\n Total\n -----\n Started:          84525        69004        14249        1272\n Finished:         82525        67004        14249        1272\n Mean:             376.0 ms     374.8 ms     379.3 ms     377.9 ms\n 95th:             491.1 ms     488.1 ms     488.2 ms     489.6 ms\n\n 200\n ---\n Count:            79208 (96%)  64320 (96%)  13663 (96%)  1225 (96%)\n Mean:             376.2 ms     381.9 ms     374.7 ms     378.1 ms\n 95th:             487.6 ms     489.0 ms     487.2 ms     490.5 ms\n\n 404\n ---\n Count:            2467 (3%)    2002 (3%)    430 (3%)     35 (3%)\n Mean:             371.4 ms     371.0 ms     377.2 ms     358.9 ms\n 95th:             487.1 ms     487.1 ms     486.0 ms     480.4 ms\n\n 500\n ---\n Count:            853 (1%)     685 (1%)     156 (1%)     12 (1%)\n Mean:             371.2 ms     370.4 ms     374.5 ms     374.3 ms\n 95th:             487.6 ms     487.1 ms     488.2 ms     466.3 ms\n\n Current rate is 10000 requests / second. Enter a new rate or press enter to view status.\n\n Rate?\n ```\n\n Config\n ======\n Blast is configured by config file, command line flags or environment variables. The `--config` flag specifies the config file to load, and can be `json`, `yaml`, `toml` or anything else that [viper](https://github.com/spf13/viper) can read. If the config flag is omitted, blast searches for `blast-config.xxx` in the current directory, `$HOME/.config/blast/` and `/etc/blast/`.\n\n Environment variables and command line flags override config file options. Environment variables are upper case and prefixed with \"BLAST\" e.g. `BLAST_PAYLOAD_TEMPLATE`.\n\n Templates\n =========\n The `payload-template` and `worker-template` options accept values that are rendered using the Go text/template system. Variables of the form `{{ .name }}` or `{{ \"name\" }}` are replaced with data.\n\n Additionally, several simple functions are available to inject random data which is useful in load testing scenarios:\n\n * `{{ rand_int -5 5 }}` - a random integer between -5 and 5.\n * `{{ rand_float -5 5 }}` - a random float between -5 and 5.\n * `{{ rand_string 10 }}` - a random string, length 10.",
-	"googleCloudOpener":          "",
-	"logRecord":                  "",
-	"loggingOpener":              "",
-	"loggingWorker":              "",
-	"mapR":                       "",
-	"metricsDef":                 "",
-	"metricsItem":                "",
-	"metricsSegment":             "",
-	"native":                     "",
-	"nativeR":                    "",
-	"opener":                     "",
-	"renderer":                   "",
-	"sliceR":                     "",
-	"templateR":                  "",
-	"threadSafeWriter":           "",
-	"threadSafeWriter.Write":     "Write writes to the underlying writer in a thread safe manner.",
-	"workDef":                    "",
+	"Blaster":                     "Blaster provides the back-end blast: a simple tool for API load testing and batch jobs. Use the New function to create a Blaster with default values.",
+	"Blaster.Arrival":             "Arrival sets the arrival distribution. See Config.Arrival for more details.",
+	"Blaster.CSV":                 "CSV sets how csv data is read. This must be set before SetData. See Config.CSV for more details.",
+	"Blaster.ChangeRate":          "ChangeRate changes the sending rate during execution.",
+	"Blaster.ChangeWorkers":       "ChangeWorkers changes the number of workers during execution. New workers are started with the rotated worker variants, and surplus workers finish their current item before they are stopped.",
+	"Blaster.CircuitBreaker":      "CircuitBreaker pauses sending when the target is failing. See Config.CircuitBreaker for more details.",
+	"Blaster.ClosedLoop":          "ClosedLoop sets the closed-loop mode. See Config.ClosedLoop for more details.",
+	"Blaster.Command":             "Command processes command line flags, loads the config and starts the blast run.",
+	"Blaster.ControlAddr":         "ControlAddr sets the address of the HTTP control server. See Config.ControlAddr for more details.",
+	"Blaster.ControlHandler":      "ControlHandler returns a http.Handler that serves the control API:\n\n\tGET  /stats            returns the stats as json\n\tPOST /rate?rate=N      changes the rate\n\tPOST /workers?count=N  changes the number of workers\n\tPOST /pause            pauses sending\n\tPOST /resume           resumes sending\n\tPOST /stop             ends the run gracefully\n\nChanges to the rate or the number of workers return 409 Conflict once the run has finished.",
+	"Blaster.CorrectLatency":      "CorrectLatency sets the correct-latency option. See Config.CorrectLatency for more details.",
+	"Blaster.DataBuffer":          "DataBuffer sets the number of items held in memory when DataOrder is `shuffle` or `random`. See\nConfig.DataBuffer for more details.",
+	"Blaster.DataFormat":          "DataFormat sets the format of the data source: `csv` (the default) or `jsonl`. This must be set\nbefore SetData. See Config.DataFormat for more details.",
+	"Blaster.DataLoop":            "DataLoop sets the number of passes over the data, or -1 to loop until the run is stopped. See\nConfig.DataLoop for more details.",
+	"Blaster.DataOrder":           "DataOrder sets the order the data is sent in: `file` (the default), `shuffle` or `random`. See\nConfig.DataOrder for more details.",
+	"Blaster.Duration":            "Duration sets the length of the run. See Config.Duration for more details.",
+	"Blaster.Exit":                "Exit cancels any goroutines that are still processing, and closes all files.",
+	"Blaster.Generate":            "Generate sets the generated data columns. See Config.Generate for more details.",
+	"Blaster.Headers":             "Headers sets the data headers. See Config.Headers for more details.",
+	"Blaster.Initialise":          "Initialise configures the Blaster with config options in a provided Config",
+	"Blaster.LoadConfig":          "LoadConfig parses command line flags and loads a config file from disk. A Config is returned which may be used with the Initialise method to complete configuration.",
+	"Blaster.LoadLogs":            "LoadLogs loads the logs from a previous run, and stores successfully completed items so they can be skipped in the current run.",
+	"Blaster.LogData":             "LogData sets the data fields to be logged. See Config.LogData for more details.",
+	"Blaster.LogOutput":           "LogOutput sets the output fields to be logged. See Config.LogOutput for more details.",
+	"Blaster.MaxFailFraction":     "MaxFailFraction ends the run when this fraction of requests have failed. See Config.MaxFailFraction for more details.",
+	"Blaster.MaxFailures":         "MaxFailures ends the run after this many failures. See Config.MaxFailures for more details.",
+	"Blaster.MaxRequests":         "MaxRequests ends the run after this many requests. See Config.MaxRequests for more details.",
+	"Blaster.MetricsAddr":         "MetricsAddr sets the address of the Prometheus metrics listener. See Config.MetricsAddr for more details.",
+	"Blaster.MetricsHandler":      "MetricsHandler returns a http.Handler that serves the metrics in Prometheus exposition format.",
+	"Blaster.OutputFormat":        "OutputFormat sets the format of the report. See Config.OutputFormat for more details.",
+	"Blaster.PayloadVariants":     "PayloadVariants sets the payload variants. See Config.PayloadVariants for more details.",
+	"Blaster.Percentiles":         "Percentiles sets the latency percentiles to report. See Config.Percentiles for more details.",
+	"Blaster.PrintStatus":         "PrintStatus prints the status message to the output writer",
+	"Blaster.Quiet":               "Quiet disables the status output.",
+	"Blaster.Rate":                "Rate sets the initial sending rate. Do not change this during a run - use the ChangeRate method instead. See Config.Resume for more details.",
+	"Blaster.RateSchedule":        "RateSchedule sets the rate schedule. See Config.RateSchedule for more details.",
+	"Blaster.ReadHeaders":         "ReadHeaders reads one row from the data source and stores that in Headers",
+	"Blaster.RegisterWorkerType":  "RegisterWorkerType registers a new worker function that can be referenced in config file by the worker-type string field.",
+	"Blaster.Report":              "Report returns the final report of the run. The config is only included if the Blaster was\nconfigured with Initialise.",
+	"Blaster.Resume":              "Resume sets the resume option. See Config.Resume for more details.",
+	"Blaster.Retry":               "Retry sets the retry policy for failed items. See Config.Retry for more details.",
+	"Blaster.Search":              "Search sets the capacity search mode. See Config.Search for more details.",
+	"Blaster.SetData":             "SetData sets the data source. The data is read as csv (configured by CSV) unless DataFormat is\n`jsonl`. If the provided io.Reader also satisfies io.Closer it will be closed on exit.",
+	"Blaster.SetInput":            "SetInput sets the rate adjustment reader, and allows testing rate adjustments. The Command method sets this to os.Stdin for interactive command line usage.",
+	"Blaster.SetLog":              "SetLog sets the log output. If the provided writer also satisfies io.Closer, it will be closed on exit.",
+	"Blaster.SetOutput":           "SetOutput sets the summary output writer, and allows the output to be redirected. The Command method sets this to os.Stdout for command line usage.",
+	"Blaster.SetPaused":           "SetPaused pauses or resumes sending during execution. While paused, ticks are skipped without reading any data.",
+	"Blaster.SetPayloadTemplate":  "SetPayloadTemplate sets the payload template. See Config.PayloadTemplate for more details.",
+	"Blaster.SetTimeout":          "SetTimeout sets the timeout. See Config.Timeout for more details. This may be called during execution.",
+	"Blaster.SetTimeseries":       "SetTimeseries sets the time-series output. If the provided writer also satisfies io.Closer, it\nwill be closed on exit.",
+	"Blaster.SetWorker":           "SetWorker sets the worker creation function. See httpworker for a simple example.",
+	"Blaster.SetWorkerTemplate":   "SetWorkerTemplate sets the worker template. See Config.WorkerTemplate for more details.",
+	"Blaster.SkipRows":            "SkipRows sets the number of lines skipped at the start of the data. This must be set before\nSetData. See Config.SkipRows for more details.",
+	"Blaster.Start":               "Start starts the blast run without processing any config.",
+	"Blaster.Stats":               "Stats returns a snapshot of the metrics (as is printed during interactive execution).",
+	"Blaster.Stop":                "Stop ends the run gracefully during execution: workers finish their current items, and the final stats are printed.",
+	"Blaster.TimeseriesFormat":    "TimeseriesFormat sets the format of the time-series output: `csv` (the default) or `json` (json\nlines). See Config.TimeseriesFile for more details.",
+	"Blaster.TimeseriesHeaders":   "TimeseriesHeaders returns the csv headers of the time-series output.",
+	"Blaster.TimeseriesInterval":  "TimeseriesInterval sets the interval between rows in the time-series output. See\nConfig.TimeseriesInterval for more details.",
+	"Blaster.WorkerVariants":      "WorkerVariants sets the worker variants. See Config.WorkerVariants for more details.",
+	"Blaster.Workers":             "Workers sets the number of workers. See Config.Workers for more details.",
+	"Blaster.WriteHTMLReport":     "WriteHTMLReport writes a self-contained html report of the run to w. It contains rate, throughput,\nlatency and error rate charts, the metrics as printed by Stats.String and the config. The charts\nneed the time-series, which is only recorded if the html report is enabled (see\nConfig.HTMLReport).",
+	"Blaster.WriteLogHeaders":     "WriteLogHeaders writes the log headers to the log writer.",
+	"Blaster.WriteReport":         "WriteReport writes the final report of the run to w in the format set by OutputFormat.",
+	"Blaster.checkFailures":       "checkFailures ends the run if max-failures or max-fail-fraction has been reached. It's called by\nthe workers after each request has finished.",
+	"Blaster.command":             "command parses and executes a line of input. Invalid input prints a message rather than ending\nthe run.",
+	"Blaster.compareCommand":      "compareCommand runs `blast compare a.json b.json`, and returns an error if regressions are found.",
+	"Blaster.currentWorkers":      "currentWorkers returns the number of workers. This may be called during execution.",
+	"Blaster.dataFile":            "dataFile returns the name of the data file currently being read, its position and the number of\nfiles. The name is empty unless the data source is several files.",
+	"Blaster.dataPasses":          "dataPasses returns the number of completed passes over the data.",
+	"Blaster.endPass":             "endPass is called at the end of each pass over the data. It returns false after the last pass,\nand otherwise re-opens the data source for the next pass (if reopen is true). An empty data source\nis never looped, because no items would be sent.",
+	"Blaster.finish":              "finish ends the run gracefully, in the same way as reaching the end of the data file. The reason\nis recorded in the stats.",
+	"Blaster.finished":            "finished returns true once the run has started to finish, after which the rate and the number of\nworkers can't be changed.",
+	"Blaster.generateFields":      "generateFields adds the generated columns to the fields of an item. Generated columns take the\nplace of data source fields with the same name.",
+	"Blaster.initialRate":         "initialRate returns the rate the ticker should start with, and the desired rate of the first\nsegment.",
+	"Blaster.listen":              "listen opens the listeners of the metrics and control servers. Both are opened before either server\nis started, so if one fails the other can be closed without leaving a server running.",
+	"Blaster.multipleDataFiles":   "multipleDataFiles returns true if the data source is several files, in which case the log records\nthe file of each item.",
+	"Blaster.newCSVReader":        "newCSVReader returns a csv reader configured by CSV.",
+	"Blaster.newDataReader":       "newDataReader returns a csv or json lines reader, depending on DataFormat. The first SkipRows lines\nare skipped.",
+	"Blaster.nextData":            "nextData returns the fields of the next item and the data file it was read from, in the order\nset by DataOrder. At the end of each pass the data is re-opened, until DataLoop passes have\ncompleted. io.EOF is returned after the last pass.",
+	"Blaster.nextRandom":          "nextRandom reads a uniform sample of up to DataBuffer items from the data source (reservoir\nsampling), and returns items picked at random from the sample. Each pass returns as many items as\nthe sample holds.",
+	"Blaster.nextShuffled":        "nextShuffled keeps DataBuffer items in memory, and returns a random one of them, replacing it\nwith the next item from the data source. Each item is returned once per pass.",
+	"Blaster.openDataSource":      "openDataSource opens a single data source: `-` for stdin, a http(s) url, a GCS object or a local\nfile. Compressed data is decompressed.",
+	"Blaster.readData":            "readData reads the fields of the next item from the data source.",
+	"Blaster.readItem":            "readItem reads the next item from the data source.",
+	"Blaster.recordFields":        "recordFields matches a csv record to the headers. Missing fields are empty.",
+	"Blaster.retry":               "retry schedules a failed item to be retried after the backoff, and returns true. If the item\nshouldn't be retried, it returns false.",
+	"Blaster.setReopenData":       "setReopenData stores a function that re-opens the data source for the next pass. Data read from\nstdin can't be re-opened.",
+	"Blaster.startControlServer":  "startControlServer serves the control API on the listener opened by listen.",
+	"Blaster.startMetricsServer":  "startMetricsServer serves the metrics on the listener opened by listen.",
+	"Blaster.startStopLoop":       "startStopLoop ends the run when the duration is reached.",
+	"Blaster.startWorker":         "startWorker creates a worker, calls Start if the worker satisfies Starter, and starts the worker\ngoroutine. Only call this from startWorkers or the workers loop.",
+	"Blaster.startWorkersLoop":    "startWorkersLoop listens for changes to the number of workers.",
+	"CSV":                         "CSV configures how csv data is read. See Config.CSV for more details.",
+	"CSV.Comma":                   "Comma sets the field delimiter, e.g. `;`, or `\\t` for tab separated data. (Default: `,`).",
+	"CSV.Comment":                 "Comment sets a character that starts a comment line, e.g. `#`. Comment lines are skipped.",
+	"CSV.FieldsPerRecord":         "FieldsPerRecord sets the number of fields in each record. If 0, each record must have the same\nnumber of fields as the first record. If -1, records may have a variable number of fields, and\nmissing fields are empty. (Default: 0).",
+	"CSV.LazyQuotes":              "LazyQuotes allows a quote to appear in an unquoted field, and a non-doubled quote to appear in a\nquoted field.",
+	"CSV.TrimLeadingSpace":        "TrimLeadingSpace ignores leading white space in a field.",
+	"CircuitBreaker":              "CircuitBreaker configures the circuit breaker. See Config.CircuitBreaker for more details.",
+	"CircuitBreaker.FailFraction": "FailFraction sets the fraction of failed requests in the window that trips the breaker. (Default: 0.5).",
+	"CircuitBreaker.MinRequests":  "MinRequests sets the minimum number of requests in the window before the breaker can trip. (Default: 20).",
+	"CircuitBreaker.Pause":        "Pause sets how long to pause sending before a probe request is sent, e.g. `5s`. (Default: `5s`).",
+	"CircuitBreaker.Window":       "Window sets the duration of the sliding window the fail fraction is measured over, e.g. `10s`. (Default: `10s`, minimum `1ms`).",
+	"Compare":                     "Compare compares the throughput, success fraction and latency percentiles of two runs: the\nsummary of all requests and each segment (matched by position), and within each of these, each\nstatus (matched by name).\nChanges for the worse beyond the tolerances are flagged as regressions.",
+	"Comparison":                  "Comparison is the result of Compare.",
+	"Comparison.Regressions":      "Regressions returns the number of differences that were flagged as regressions.",
+	"Comparison.String":           "String returns a table of the differences.",
+	"Config":                      "Config provides all the standard config options. Use the Initialise method to configure with a provided Config.",
+	"Config.Arrival":              "Arrival sets the distribution of the intervals between requests: `constant` (the default) sends at a fixed interval, `poisson` draws exponentially distributed intervals to simulate independent users, and `uniform-jitter` draws intervals uniformly between 0.5 and 1.5 times the fixed interval. The mean interval is the same for each distribution, so the actual rate converges on the desired rate.",
+	"Config.CSV":                  "CSV sets how csv data is read: `comma` sets the field delimiter (e.g. `;`, or `\\t` for tab separated data), `comment` sets a character that starts a comment line (e.g. `#`), `lazy-quotes` allows quotes to appear in unquoted fields and non-doubled quotes in quoted fields, `trim-leading-space` ignores leading white space in fields, and `fields-per-record` sets the number of fields in each record (`0`, the default, requires every record to have the same number of fields as the first, and `-1` allows records with a variable number of fields, where missing fields are empty). When setting this by command line flag or environment variable, use a json encoded string, e.g. `{\"comma\": \";\"}`.",
+	"Config.CircuitBreaker":       "CircuitBreaker pauses sending when the target is failing. The breaker trips when more than `fail-fraction` (default `0.5`) of the requests in a sliding `window` (default `10s`) have failed, as long as the window contains at least `min-requests` (default `20`) requests. While the breaker is open, ticks are skipped without reading any data, so no items are used up. After `pause` (default `5s`) a single probe request is sent: if it succeeds the breaker closes and sending resumes, and if it fails the breaker stays open for another pause. With `payload-variants`, only the first variant of the probe item is the probe: the other variants are still sent, but their results don't change the state of the breaker. The `window` must be at least `1ms`. The state of the breaker is shown in the status output. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.ClosedLoop":           "ClosedLoop runs without a rate limit: each worker requests the next item as soon as its previous request has finished, so concurrency is bounded only by the number of workers. Use this to find the maximum throughput of the target - the actual rate is reported in the metrics. The `rate` option is ignored, and `rate-schedule` can't be used in closed-loop mode.",
+	"Config.ControlAddr":          "ControlAddr sets the address of a local HTTP control server, e.g. `localhost:8081`. Use `GET /stats` to get the stats as json, `POST /rate?rate=N` to change the rate, `POST /workers?count=N` to change the number of workers, `POST /pause` and `POST /resume` to pause and resume sending, and `POST /stop` to end the run gracefully. The server has no authentication, so bind it to a local address.",
+	"Config.CorrectLatency":       "CorrectLatency measures latency from the time each item should have been sent, rather than from the time a worker picks it up. Ticks that find no idle worker are queued instead of skipped, so the latency percentiles include time spent waiting for a worker. Queued ticks are shown as delayed rather than missed in the status. At most 100000 ticks are queued, and further ticks are dropped and counted as missed. This corrects for coordinated omission when the target is overloaded.",
+	"Config.Data":                 "Data sets the data source. If none is specified, the worker will be called repeatedly until interrupted (useful for load testing). Data should be in csv format, and if `headers` is not specified the first record will be used as the headers. Json lines data is also supported (see `data-format`). Load a local file, or stream from a GCS bucket with `gs://{bucket}/{filename}.csv`. A glob pattern (e.g. `shards/*.csv`), a directory or a GCS prefix ending in a slash (e.g. `gs://{bucket}/{prefix}/`) reads several files one after another. Each file must have the same headers, the status shows the current file, and the log records the file of each item. A file that exists is read as is, even if its name contains glob characters. A `http://` or `https://` url is streamed. If the connection drops and the server supports range requests, blast resumes where it stopped, otherwise the run ends with an error. Use `-` to read from stdin (interactive commands are then disabled). Gzip and zstd compressed data is decompressed, detected by the extension (`.gz` or `.zst`) or the first bytes of the data. Rows can be read from a database with `{driver}://{dsn}?query={query}`, e.g. `sqlite://jobs.db?query=SELECT id, name FROM jobs ORDER BY id`, and the column names are used as the headers. The query must be the last parameter, and is url decoded, so a literal `+` or `%` must be encoded as `%2B` or `%25`. The `skip-rows`, `data-format` and `csv` options can't be used with a database. The sqlite driver is included, and other database/sql drivers can be registered when using blast from code. If a newline character is found, this string is read as the data.",
+	"Config.DataBuffer":           "DataBuffer sets the number of items held in memory when `data-order` is `shuffle` or `random`. (Default: 10000).",
+	"Config.DataFormat":           "DataFormat sets the format of the data: `csv` or `jsonl` (json lines). If omitted, files ending `.jsonl` or `.ndjson` are read as json lines, and everything else as csv. Each line of json lines data is a json object, and each top-level key is available as a template field. Nested objects and arrays (and numbers and booleans) are available as json strings, and `headers` is not used.",
+	"Config.DataLoop":             "DataLoop replays the data source several times, e.g. to run a load test from a corpus of IDs. Set to the number of passes over the data, or `-1` to loop until the run is stopped by `duration`, `max-requests` or by the user. The data is re-opened at the start of each pass, so this can't be used when the data is read from stdin (unless `data-order` is `random`). The number of completed passes is shown in the status. (Default: 0 - the data is read once).",
+	"Config.DataOrder":            "DataOrder sets the order the data is sent in: `file` (the order of the data source), `shuffle` or `random`. `shuffle` reads `data-buffer` items ahead and sends a random one of them, so each item is sent once per pass in a shuffled order without reading the whole data source into memory. `random` samples items at random (with replacement) from the data: a uniform sample of up to `data-buffer` items is read into memory, and each pass sends as many items as the sample holds. (Default: file).",
+	"Config.Duration":             "Duration sets the length of the run, e.g. `30s` or `5m`. When the duration is reached, workers finish their current items and the run ends cleanly, as it does at the end of the data file. If omitted, blast runs until the data is exhausted or it is interrupted.",
+	"Config.Generate":             "Generate adds generated columns to each item, so synthetic data can be sent without a data file. Each generator has a `name` (the name of the field) and a `type`: `sequence` counts from `start` (default `0`) in increments of `step` (default `1`), `uuid` is a random uuid, `int` is a random whole number between `min` and `max` (inclusive), `float` is a random number between `min` and `max` (rounded to `decimals` places if set), `choice` picks one of `choices` at random (weighted by `weights` if set), and `timestamp` is the current time formatted with `format`: a Go time layout (default RFC3339), `unix` or `unix-ms`. Generated columns are available as template fields in the same way as data columns, and may be used with `data` (a generated column replaces a data column with the same name). They can be logged with `log-data`, and are included in the hash used by `resume`, so only `sequence` columns can be used in resume mode (the other types change between runs, so no items would be skipped). When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.HTMLReport":           "HTMLReport sets the filename of a self-contained html report that is written at the end of the run. The report contains rate, throughput, latency percentile and error rate charts over time (recorded every `timeseries-interval`), the metrics of every segment as printed in the status, and the config. It has no external dependencies, so it can be viewed offline or attached to a ticket.",
+	"Config.Headers":              "Headers sets the data file headers. If omitted, the first record of the csv data source is used. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.Log":                  "Log sets the filename of the log file to create / append to.",
+	"Config.LogData":              "LogData sets an array of data fields to include in the output log. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.LogOutput":            "LogOutput sets an array of worker response fields to include in the output log. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.MaxFailFraction":      "MaxFailFraction ends the run cleanly when the fraction of failed requests exceeds this value, e.g. `0.05`. To avoid stopping on the first few results, the fraction is only checked after 100 requests have finished. (Default: no limit).",
+	"Config.MaxFailures":          "MaxFailures ends the run cleanly when this many requests have failed. (Default: no limit).",
+	"Config.MaxRequests":          "MaxRequests ends the run cleanly after this many requests have been sent. (Default: no limit).",
+	"Config.MetricsAddr":          "MetricsAddr sets the address of a listener that serves live metrics in Prometheus exposition format at `/metrics`, e.g. `:9090`. Request counters and latency histograms are labelled by segment and status, and busy workers, skipped items and the desired and actual rates are also included.",
+	"Config.OutputFormat":         "OutputFormat sets the format of the report file: `text` or `json`. (Default: `text`).",
+	"Config.PayloadTemplate":      "PayloadTemplate sets the template that is rendered and passed to the worker `Send` method. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.PayloadVariants":      "PayloadVariants sets an array of maps that will cause each data item to be repeated with the provided data. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.Percentiles":          "Percentiles sets the latency percentiles that are reported for each segment and status, e.g. `[50, 99, 99.9]`. Latencies are recorded in a high dynamic range histogram, so every request is counted and the tail percentiles are accurate. The maximum latency is always reported. (Default: `[50, 90, 95, 99, 99.9]`). When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.Quiet":                "Quiet instructs the tool to prevent interactive features. No summary is printed during operation and the rate cannot be changed interactively.",
+	"Config.Rate":                 "Rate sets the initial rate in requests per second. Simply enter a new rate during execution to adjust this. (Default: 10 requests / second).",
+	"Config.RateSchedule":         "RateSchedule sets a list of stages that change the rate automatically. Each stage has a target `rate`, a `duration` (e.g. `30s`) and a `shape`: `step` (the default) jumps straight to the target rate, and `linear` ramps from the previous rate to the target rate over the duration. A new metrics segment is created for each stage, and after the final stage the rate is held. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.ReportFile":           "ReportFile sets the filename of a report that is written at the end of the run. The report contains the final metrics (every segment, total and status), the config, the start and end times and the reason the run stopped. Durations in the json report are in nanoseconds.",
+	"Config.Resume":               "Resume instructs the tool to load the log file and skip previously successful items. Failed items will be retried.",
+	"Config.Retry":                "Retry sets a retry policy for failed items. Failed items are retried up to `max-attempts` times in total (default `3`), after an exponential backoff: the first retry waits for `backoff` (default `100ms`), and each subsequent delay is multiplied by `multiplier` (default `2`) up to `max-backoff` (default `10s`). Each delay is randomised by up to the `jitter` fraction (default `0.2`). If `statuses` is specified, only items that failed with one of these statuses are retried. Retries are rate limited in the same way as fresh items, and the run waits for outstanding retries before finishing at the end of the data. Every attempt counts as a request in the metrics, and retries are also counted separately. When a log is written, an `attempt` column is included. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.Search":               "Search runs a capacity search: the rate is raised segment by segment until a segment fails, then a binary search finds the highest sustainable rate, which is reported in the final output. A segment fails if the fraction of failed requests exceeds `fail-fraction` (default `0.01`), the 95th percentile latency exceeds `p95` (if set), or the actual rate falls more than 10% short of the desired rate. Each segment lasts for `duration` (e.g. `30s`, required). The first segment uses the `start` rate (default: the `rate` option), and the rate is multiplied by `factor` (default `2`) until a segment fails. The search stops when the gap between the highest passing rate and the lowest failing rate is less than `precision` (default `0.05`) of the failing rate, and the run then finishes. If every segment fails, the rate is halved until it falls below 1% of the `start` rate, and then the search finishes with no sustainable rate. Search can't be used with `rate-schedule` or in closed-loop mode. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.SkipRows":             "SkipRows skips this number of lines at the start of the data (e.g. a preamble before the headers). When reading several files, the lines are skipped at the start of each file.",
+	"Config.Timeout":              "Timeout sets the deadline in the context passed to the worker. Workers must respect this the context cancellation. We exit with an error if any worker is processing for timeout + 1 second. (Default: 1 second).",
+	"Config.TimeseriesFile":       "TimeseriesFile sets the filename of a time-series file, which is written with one row per interval (see `timeseries-interval`) during the run. Each row contains the time, the current segment and its desired rate, the number of requests started, finished, succeeded and failed during the interval, the number of busy workers, and the mean, percentile (see `percentiles`) and maximum latency of requests that finished during the interval. If the filename ends in `.json` or `.jsonl` rows are written as json lines (with durations in nanoseconds), otherwise as csv (with durations in milliseconds).",
+	"Config.TimeseriesInterval":   "TimeseriesInterval sets the interval between rows in the time-series file, e.g. `1s` or `500ms`. (Default: 1s).",
+	"Config.WorkerTemplate":       "WorkerTemplate sets a template to render and pass to the worker `Start` or `Stop` methods if the worker satisfies the `Starter` or `Stopper` interfaces. Use with `worker-variants` to configure several workers differently to spread load. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.WorkerType":           "WorkerType sets the selected worker type. Register new worker types with the `RegisterWorkerType` method.",
+	"Config.WorkerVariants":       "WorkerVariants sets an array of maps that will cause each worker to be initialised with different data. When setting this by command line flag or environment variable, use a json encoded string.",
+	"Config.Workers":              "Workers sets the number of concurrent workers. (Default: 10 workers).",
+	"DefaultTolerances":           "DefaultTolerances are the tolerances used by the compare command if none are specified.",
+	"Difference":                  "Difference is the change in a single metric between two runs.",
+	"DummyCloser":                 "",
+	"ExampleWorker":               "ExampleWorker facilitates code examples by satisfying the Worker, Starter and Stopper interfaces with provided functions.",
+	"ExampleWorker.Send":          "Send satisfies the Worker interface.",
+	"ExampleWorker.Start":         "Start satisfies the Starter interface.",
+	"ExampleWorker.Stop":          "Stop satisfies the Stopper interface.",
+	"Generator":                   "Generator configures a generated data column. See Config.Generate for more details.",
+	"Generator.Choices":           "Choices sets the values of a `choice`.",
+	"Generator.Decimals":          "Decimals rounds a `float` to this number of decimal places. (Default: 0 - full precision).",
+	"Generator.Format":            "Format sets the format of a `timestamp`: a Go time layout, `unix` (seconds) or `unix-ms`\n(milliseconds). (Default: RFC3339).",
+	"Generator.Max":               "Max sets the maximum value of an `int` or `float`. For `int` the maximum is inclusive.",
+	"Generator.Min":               "Min sets the minimum value of an `int` or `float`.",
+	"Generator.Name":              "Name sets the name of the field.",
+	"Generator.Start":             "Start sets the first value of a `sequence`. (Default: 0).",
+	"Generator.Step":              "Step sets the increment of a `sequence`. (Default: 1).",
+	"Generator.Type":              "Type sets the type of the generator: `sequence`, `uuid`, `int`, `float`, `choice` or `timestamp`.",
+	"Generator.Weights":           "Weights sets the relative weight of each of the choices, e.g. `[3, 1]` picks the first choice\nthree times as often as the second. (Default: all choices are equally likely).",
+	"LoadReport":                  "LoadReport reads a report that was written in json format. See Config.ReportFile for more details.",
+	"LoggingReadWriteCloser":      "",
+	"LoggingWorker":               "",
+	"LoggingWriter":               "",
+	"New":                         "New creates a new Blaster with defaults.",
+	"RateStage":                   "RateStage is a stage in the rate schedule. See Config.RateSchedule for more details.",
+	"RateStage.Duration":          "Duration sets the duration of this stage, e.g. `30s` or `5m`.",
+	"RateStage.Rate":              "Rate sets the target rate of this stage in requests per second.",
+	"RateStage.Shape":             "Shape sets the shape of this stage: `step` (the default) jumps straight to the target rate, and `linear` ramps from the previous rate to the target rate over the duration of the stage.",
+	"Report":                      "Report is the final report of a run, written to the report file. See Config.ReportFile for more\ndetails.",
+	"Report.String":               "String returns a text representation of the report.",
+	"Retry":                       "Retry configures the retry policy. See Config.Retry for more details.",
+	"Retry.Backoff":               "Backoff sets the delay before the first retry, e.g. `500ms`. (Default: `100ms`).",
+	"Retry.Jitter":                "Jitter randomises each delay by up to this fraction, e.g. `0.2` gives delays between 80% and 120% of the backoff. (Default: 0.2).",
+	"Retry.MaxAttempts":           "MaxAttempts sets the maximum number of attempts for each item, including the first. (Default: 3).",
+	"Retry.MaxBackoff":            "MaxBackoff sets the maximum delay between retries, e.g. `30s`. (Default: `10s`).",
+	"Retry.Multiplier":            "Multiplier sets the factor the delay is multiplied by after each retry. (Default: 2).",
+	"Retry.Statuses":              "Statuses sets the statuses that should be retried, e.g. `[\"500\", \"503\"]`. If omitted, all failed items are retried.",
+	"Search":                      "Search configures the capacity search mode. See Config.Search for more details.",
+	"Search.Duration":             "Duration sets the duration of each segment, e.g. `30s`.",
+	"Search.Factor":               "Factor sets the multiplier used to raise the rate until a threshold is crossed. (Default: 2).",
+	"Search.FailFraction":         "FailFraction sets the highest acceptable fraction of failed requests in a segment, e.g. `0.01`. (Default: 0.01).",
+	"Search.P95":                  "P95 sets the highest acceptable 95th percentile latency in a segment, e.g. `200ms`. If omitted, latency is not checked.",
+	"Search.Precision":            "Precision sets the relative precision of the result: the binary search stops when the gap between the highest passing rate and the lowest failing rate is less than this fraction of the failing rate. (Default: 0.05).",
+	"Search.Start":                "Start sets the rate of the first segment. (Default: the rate option).",
+	"Segment":                     "Segment is a rate segment - a new segment is created each time the rate is changed.",
+	"Starter":                     "Starter and Stopper are interfaces a worker can optionally satisfy to provide initialization or finalization logic. See `httpworker` and `dummyworker` for simple examples.",
+	"Stats":                       "Stats is a snapshot of the metrics (as is printed during interactive execution).",
+	"Stats.String":                "String returns a string representation of the stats (as is printed during interactive execution).",
+	"Stats.percentileLabels":      "percentileLabels returns the row labels for the percentiles, e.g. \"99.9th\". If no percentiles are\nconfigured, only the 95th is printed.",
+	"Status":                      "Status is a summary of all requests that returned a specific status",
+	"Stopper":                     "Stopper is an interface a worker can optionally satisfy to provide finalization logic.",
+	"ThreadSafeBuffer":            "",
+	"TimeseriesRow":               "TimeseriesRow is a row in the time-series output. It holds the metrics of a single interval. See\nConfig.TimeseriesFile for more details.",
+	"TimeseriesRow.toCsv":         "toCsv returns the csv record of the row. Durations are in milliseconds.",
+	"Tolerances":                  "Tolerances sets the changes between two runs that Compare allows before flagging a regression.",
+	"Tolerances.Latency":          "Latency is the largest allowed fractional increase in each latency percentile, e.g. 0.1 for 10%.",
+	"Tolerances.Success":          "Success is the largest allowed decrease in the success fraction, e.g. 0.01 for one percentage\npoint.",
+	"Tolerances.Throughput":       "Throughput is the largest allowed fractional decrease in throughput (finished requests per\nsecond), e.g. 0.05 for 5%.",
+	"Total":                       "Total is the summary of all requests in this segment",
+	"Worker":                      "Worker is an interface that allows blast to easily be extended to support any protocol. See `main.go` for an example of how to build a command with your custom worker type.",
+	"arrivals":                    "arrivals maps the arrival option to a function returning the interval until the next tick as a\nmultiple of the mean interval. Each distribution has a mean of 1, so the actual rate converges on\nthe desired rate.",
+	"breakerBucket":               "",
+	"breakerBuckets":              "breakerBuckets is the number of buckets the circuit breaker window is divided into.",
+	"breakerDef":                  "",
+	"breakerDef.allow":            "allow is called by the main loop for each tick, and returns true if an item should be sent. When\nthe breaker is half-open, probe is true and only one item is allowed at a time. Only the first\npayload variant of that item is flagged as the probe: the others are sent, but while the breaker\nisn't closed their results are ignored by record.",
+	"breakerDef.record":           "record is called by the workers when each item has finished. It returns a message if the state\nof the breaker changes.",
+	"breakerDef.release":          "release is called by the main loop if it was allowed to send a probe but had no item to send.",
+	"breakerDef.status":           "status returns the state of the breaker and the number of times it has tripped.",
+	"chartSeries":                 "",
+	"chunkServer":                 "chunkServer serves data with range requests, dropping the connection after each chunk. If\nrestart is true, resumed requests start from the beginning of the data.",
+	"closedLoopIdleInterval":      "closedLoopIdleInterval is how long the main loop waits in closed-loop mode when it has nothing to\nsend (e.g. sending is paused), so it doesn't spin on requests from the workers.",
+	"compression":                 "compression returns the compression of a data source by extension: `gzip`, `zstd` or an empty\nstring.",
+	"csvReader":                   "",
+	"csvRune":                     "csvRune returns the single character of a csv delimiter, or 0 if it's empty.",
+	"csvWriteFlusher":             "",
+	"cumulativeWeights":           "cumulativeWeights returns the running total of the weights of each choice. If weights is empty, all\nchoices have the same weight.",
+	"dataFormat":                  "dataFormat chooses the data format by extension. The extension of compressed data is ignored,\ne.g. `data.jsonl.gz` is json lines.",
+	"dataItem":                    "",
+	"dataOrderDef":                "dataOrderDef holds the state of the data source between items: the number of completed passes\nover the data, and the buffer of items when the data is shuffled or sampled at random. Apart from\npasses, it is only accessed by the main loop.",
+	"debug":                       "Set debug to true to print the number of active goroutines with every status.",
+	"decompress":                  "decompress wraps r in a gzip or zstd reader if the data is compressed. Compression is detected by\nthe extension of the name, or by the magic bytes at the start of the data. If r satisfies\nio.Closer, the returned reader closes it.",
+	"decompressReader":            "decompressReader closes the decompressor and the underlying reader.",
+	"doc_go":                      "Package blaster provides the back-end for blast - a tool for load testing and sending api requests in bulk.\n\n Blast\n =====\n\n * Blast makes API requests at a fixed rate, or as fast as the workers allow in closed-loop mode.\n * The number of concurrent workers is configurable.\n * The rate may be changed interactively during execution, or automatically with a rate schedule.\n * For capacity planning: a search mode finds the highest rate the target can sustain.\n * Runs can end after a duration, a number of requests or when too many requests fail.\n * Blast is protocol agnostic, and adding a new worker type is trivial.\n * For load testing: random data can be added to API requests.\n * For batch jobs: CSV data can be loaded from local file or GCS bucket, failed items can be retried with backoff, and successful items from previous runs are skipped.\n\n Installation\n ============\n ## Mac\n ```\n brew tap dave/blast\n brew install blast\n ```\n\n ## Linux\n See the [releases page](https://github.com/dave/blast/releases)\n\n ## From source\n ```\n go get -u github.com/dave/blast\n ```\n\n Examples\n ========\n Using the dummy worker to send at 20,000 requests per second (the dummy worker returns after a random wait, and occasionally returns errors):\n ```\n blast --rate=20000 --workers=1000 --worker-type=\"dummy\" --worker-template='{\"min\":25,\"max\":50}'\n ```\n\n Using the http worker to request Google's homepage at one request per second (warning: this is making real http requests - don't turn the rate up!):\n ```\n blast --rate=1 --worker-type=\"http\" --payload-template='{\"method\":\"GET\",\"url\":\"http://www.google.com/\"}'\n ```\n\n Status\n ======\n\n Blast prints a summary every ten seconds. While blast is running, you can hit enter for an updated\n summary, or enter a command: `rate N` (or just a number) changes the sending rate, `workers N`\n changes the number of workers, `timeout 500ms` changes the worker timeout, `pause` and `resume`\n pause and resume sending, `stop` finishes the current items and ends the run, and `help` lists\n the commands. Each time you change the rate a new column of metrics is created. If the worker\n returns a field named `status` in its response, the values are summarised as rows. Latency is\n summarised by the mean, the percentiles set by the `percentiles` option (the example below shows\n only the 95th) and the maximum.\n\n Here's an example of the output:\n\n ```\n Metrics\n =======\n Concurrency:      1999 / 2000 workers in use\n\n Desired rate:     (all)        10000        1000         100\n Actual rate:      2112         5354         989          100\n Avg concurrency:  1733         1976         367          37\n Duration:         00:40        00:12        00:14        00:12\n\n Total\n -----\n Started:          84525        69004        14249        1272\n Finished:         82525        67004        14249        1272\n Mean:             376.0 ms     374.8 ms     379.3 ms     377.9 ms\n 95th:             491.1 ms     488.1 ms     488.2 ms     489.6 ms\n\n 200\n ---\n Count:            79208 (96%)  64320 (96%)  13663 (96%)  1225 (96%)\n Mean:             376.2 ms     381.9 ms     374.7 ms     378.1 ms\n 95th:             487.6 ms     489.0 ms     487.2 ms     490.5 ms\n\n 404\n ---\n Count:            2467 (3%)    2002 (3%)    430 (3%)     35 (3%)\n Mean:             371.4 ms     371.0 ms     377.2 ms     358.9 ms\n 95th:             487.1 ms     487.1 ms     486.0 ms     480.4 ms\n\n 500\n ---\n Count:            853 (1%)     685 (1%)     156 (1%)     12 (1%)\n Mean:             371.2 ms     370.4 ms     374.5 ms     374.3 ms\n 95th:             487.6 ms     487.1 ms     488.2 ms     466.3 ms\n\n Current rate is 10000 requests / second. Enter a command (e.g. \"rate 100\"), \"help\" for a list of commands, or press enter to view status.\n\n Rate?\n ```\n\n Config\n ======\n Blast is configured by config file, command line flags or environment variables. The `--config` flag specifies the config file to load, and can be `json`, `yaml`, `toml` or anything else that [viper](https://github.com/spf13/viper) can read. If the config flag is omitted, blast searches for `blast-config.xxx` in the current directory, `$HOME/.config/blast/` and `/etc/blast/`.\n\n Environment variables and command line flags override config file options. Environment variables are upper case and prefixed with \"BLAST\" e.g. `BLAST_PAYLOAD_TEMPLATE`.\n\n Comparing runs\n ==============\n `blast compare a.json b.json` compares two reports written with `output-format` set to `json` (see\n `report-file`). The throughput, success fraction and latency percentiles of all requests and each\n segment are compared, along with each status within them, and changes for the worse beyond the\n tolerances are flagged as regressions. The `--throughput` (default 0.05), `--success` (default 0.01) and `--latency`\n (default 0.1) flags set the largest allowed fractional decrease in throughput, decrease in the\n success fraction and fractional increase in latency. Blast exits with a non-zero status if any\n regressions are found, so it can be used to gate a deploy.\n\n Templates\n =========\n The `payload-template` and `worker-template` options accept values that are rendered using the Go text/template system. Variables of the form `{{ .name }}` or `{{ \"name\" }}` are replaced with data.\n\n Additionally, several simple functions are available to inject random data which is useful in load testing scenarios:\n\n * `{{ rand_int -5 5 }}` - a random integer between -5 and 5.\n * `{{ rand_float -5 5 }}` - a random float between -5 and 5.\n * `{{ rand_string 10 }}` - a random string, length 10.",
+	"dropServer":                  "dropServer serves data, dropping the connection after the first half of the body. If ranges is\ntrue, range requests are supported.",
+	"generatorDef":                "",
+	"googleCloudOpener":           "",
+	"histogram":                   "histogram is a high dynamic range latency histogram. Unlike a sampled reservoir, every value is\ncounted, so the tail percentiles are accurate. Values are recorded in microseconds.",
+	"histogram.Count":             "Count returns the number of recorded values.",
+	"histogram.Cumulative":        "Cumulative returns the number of recorded values less than or equal to each of the bounds, which\nmust be in ascending order.",
+	"histogram.Max":               "Max returns the largest recorded value.",
+	"histogram.Mean":              "Mean returns the mean of the recorded values.",
+	"histogram.Percentile":        "Percentile returns the value below which p percent of the recorded values fall, e.g.\nPercentile(99.9).",
+	"histogram.Percentiles":       "Percentiles returns the values at each of the percentiles.",
+	"histogram.Sum":               "Sum returns the sum of the recorded values.",
+	"histogram.Update":            "Update records a duration.",
+	"histogramIndex":              "histogramIndex returns the bucket index for a value.",
+	"histogramSubBits":            "histogramSubBits sets the precision of the histogram: values below 2^histogramSubBits are recorded\nexactly, and larger values in log-linear buckets with 2^(histogramSubBits-1) (1024) sub-buckets per\npower of two. Each bucket is less than 1/1024 of its values wide, so the error is less than 0.1%.",
+	"histogramValue":              "histogramValue returns the highest value that would be recorded in a bucket.",
+	"httpDataBackoff":             "httpDataBackoff is the wait before the first reconnection. It doubles for each retry.",
+	"httpDataRetries":             "httpDataRetries is the number of times in a row the http data reader reconnects after the\nconnection drops. The count is reset when data is read after a reconnection.",
+	"httpReader":                  "httpReader streams data from a http(s) url. If the connection drops and the server supports range\nrequests, the reader reconnects and resumes from where it stopped. Otherwise the error is\nreturned, which ends the run with a fatal error rather than silently truncating the data.",
+	"httpReader.Close":            "Close closes the connection.",
+	"jsonlReader":                 "jsonlReader reads json lines data: each line is a json object, and each top-level key is a field.\nString values are used as they are, null values are empty, and all other values (numbers,\nbooleans, nested objects and arrays) are compact json strings.",
+	"jsonlReader.Read":            "Read returns the values of the next item, sorted by key. The main loop uses ReadMap, so this is\nonly used to satisfy csvReader.",
+	"jsonlReader.ReadMap":         "ReadMap returns the fields of the next item. Blank lines are skipped.",
+	"latencies":                   "latencies returns the percentiles and latencies (in milliseconds) of a total or status. Reports\nwithout percentiles only have the 95th.",
+	"logRecord":                   "",
+	"loggingOpener":               "",
+	"loggingWorker":               "",
+	"mapOpener":                   "mapOpener serves GCS objects from a map of names to contents.",
+	"mapR":                        "",
+	"mapReader":                   "mapReader is satisfied by data readers that read each item as a map of fields rather than a\nrecord that is matched to Headers (e.g. json lines).",
+	"maxBacklog":                  "maxBacklog is the number of ticks that may be queued in correct-latency mode. Further ticks are\ndropped (and counted as missed), so a sustained overload doesn't grow memory without limit.",
+	"maxInt":                      "maxInt is 2^63, the first float64 that doesn't fit in an int64.",
+	"maxTickerLag":                "maxTickerLag is how far the ticker may fall behind before it stops trying to catch up.",
+	"metricsDef":                  "",
+	"metricsDef.failures":         "failures returns the total number of failed and finished requests.",
+	"metricsDef.setStopReason":    "setStopReason records the reason the run ended. Only the first reason is recorded.",
+	"metricsDef.timeseries":       "timeseries returns the metrics of the current interval and starts a new interval.",
+	"metricsInterval":             "metricsInterval accumulates the metrics of the current interval. It's protected by the metricsDef\nmutex.",
+	"metricsItem":                 "",
+	"metricsSegment":              "",
+	"minBreakerWindow":            "minBreakerWindow is the shortest circuit breaker window, so each bucket has a useful duration.",
+	"minFailFractionSample":       "minFailFractionSample is the number of requests that must finish before max-fail-fraction is\nchecked.",
+	"multiReader":                 "multiReader reads several data files one after another as a single data source. If headers is\ntrue, each file starts with a header record: the headers of the first file are used, and every\nother file must have the same headers.",
+	"multiReader.Close":           "Close closes the current file.",
+	"multiReader.Read":            "Read returns the next record, opening the next file when the current file is finished.",
+	"multiReader.ReadMap":         "ReadMap returns the fields of the next item, opening the next file when the current file is\nfinished.",
+	"multiReader.current":         "current returns the name of the file currently being read, its position and the number of files.",
+	"multiReader.next":            "next closes the current file and opens the next. It returns io.EOF after the last file.",
+	"native":                      "",
+	"nativeR":                     "",
+	"niceCeiling":                 "niceCeiling returns a round number that is at least v, so the axis labels are readable.",
+	"openSQLData":                 "openSQLData runs the query, which is url decoded as a query parameter: `+` is a space, so a literal\n`+` or `%` must be encoded as `%2B` or `%25`.",
+	"opener":                      "",
+	"parseGenerators":             "parseGenerators parses the generated columns, which must have unique names.",
+	"pathExists":                  "listData returns the files matched by a glob pattern, a local directory or a GCS prefix (ending in\na slash), sorted by name. If the value is a single data source, nil is returned.\npathExists returns true if the file or directory exists.",
+	"prometheusBuckets":           "prometheusBuckets are the upper bounds of the latency histogram buckets.",
+	"rateChange":                  "rateChange is sent by the schedule loop to adjust the rate of the ticker.",
+	"readFiles":                   "readFiles reads all items, and returns the file and the \"a\" and \"b\" fields of each.",
+	"renderer":                    "",
+	"resumableGenerators":         "resumableGenerators returns true if every generator produces the same values on each run, so the\nhash of the data is the same when a run is resumed.",
+	"retryDef":                    "",
+	"retryDef.delay":              "delay returns the backoff before the next attempt.",
+	"retryDef.retryable":          "retryable returns true if a failed item with this status should be retried after this attempt.",
+	"retryQueue":                  "retryQueue holds failed items that are ready to be retried. The main loop sends these to the\nworkers in place of fresh items, so retries are rate limited.",
+	"retryQueue.add":              "add registers a fresh item sent to the workers.",
+	"retryQueue.done":             "done returns true if no items are waiting to be retried or might still need to be retried.",
+	"retryQueue.outstanding":      "outstanding is the number of items that have been sent to the workers (or are waiting to be\nretried) but haven't yet reached a final result.",
+	"retryQueue.pop":              "pop returns the oldest item that's ready to be retried.",
+	"retryQueue.push":             "push adds an item that's ready to be retried.",
+	"retryQueue.resolve":          "resolve registers an item that has reached a final result.",
+	"runLogged":                   "runLogged runs b with a single worker, and returns the value of field a of each item in the log.",
+	"runLoggedTo":                 "runLoggedTo runs b in the same way as runLogged, writing the log to buf.",
+	"scheduleResolution":          "scheduleResolution is the interval between rate adjustments during a linear stage.",
+	"searchDef":                   "",
+	"searchDef.passed":            "passed returns true if the segment is within the thresholds.",
+	"searchFloor":                 "searchFloor is the fraction of the start rate below which the search gives up: if every rate down to\nthis has failed, no sustainable rate is found.",
+	"skipReader":                  "skipReader skips a number of lines at the start of the data (e.g. a preamble before the headers).",
+	"sliceR":                      "",
+	"splitSQL":                    "splitSQL splits a sql data source of the form `<driver>://<dsn>?query=<query>` into the driver\nname, the data source name and the query, which is still url encoded. The query parameter must be\nlast, so the query may contain any characters. ok is false unless the scheme is a registered\ndatabase/sql driver.",
+	"sqlReader":                   "sqlReader streams the rows of a sql query. Each row is a record, matched to the column names.",
+	"sqlReader.Close":             "Close closes the rows and the database.",
+	"sqlReader.Read":              "Read returns the values of the next row. Null values are empty, and times are formatted as\nRFC3339 with nanoseconds.",
+	"svgChart":                    "svgChart renders a line chart as inline svg, so the report has no external dependencies.",
+	"templateR":                   "",
+	"threadSafeWriter":            "",
+	"threadSafeWriter.Write":      "Write writes to the underlying writer in a thread safe manner.",
+	"throughput":                  "throughput returns the number of finished requests per second.",
+	"tickDef":                     "tickDef is sent by the ticker loop to the main loop for each tick.",
+	"uuid":                        "uuid returns a random (version 4) uuid.",
+	"workDef":                     "",
 }
@@ -4,12 +4,14 @@ Package blaster provides the back-end for blast - a tool for load testing and se
  Blast
  =====
 
- * Blast makes API requests at a fixed rate.
+ * Blast makes API requests at a fixed rate, or as fast as the workers allow in closed-loop mode.
  * The number of concurrent workers is configurable.
- * The rate may be changed interactively during execution.
+ * The rate may be changed interactively during execution, or automatically with a rate schedule.
+ * For capacity planning: a search mode finds the highest rate the target can sustain.
+ * Runs can end after a duration, a number of requests or when too many requests fail.
  * Blast is protocol agnostic, and adding a new worker type is trivial.
  * For load testing: random data can be added to API requests.
- * For batch jobs: CSV data can be loaded from local file or GCS bucket, and successful items from previous runs are skipped.
+ * For batch jobs: CSV data can be loaded from local file or GCS bucket, failed items can be retried with backoff, and successful items from previous runs are skipped.
 
  Installation
  ============
@@ -43,9 +45,13 @@ Package blaster provides the back-end for blast - a tool for load testing and se
  ======
 
  Blast prints a summary every ten seconds. While blast is running, you can hit enter for an updated
- summary, or enter a number to change the sending rate. Each time you change the rate a new column
- of metrics is created. If the worker returns a field named `status` in it's response, the values
- are summarised as rows.
+ summary, or enter a command: `rate N` (or just a number) changes the sending rate, `workers N`
+ changes the number of workers, `timeout 500ms` changes the worker timeout, `pause` and `resume`
+ pause and resume sending, `stop` finishes the current items and ends the run, and `help` lists
+ the commands. Each time you change the rate a new column of metrics is created. If the worker
+ returns a field named `status` in its response, the values are summarised as rows. Latency is
+ summarised by the mean, the percentiles set by the `percentiles` option (the example below shows
+ only the 95th) and the maximum.
 
  Here's an example of the output:
 
@@ -84,7 +90,7 @@ Package blaster provides the back-end for blast - a tool for load testing and se
  Mean:             371.2 ms     370.4 ms     374.5 ms     374.3 ms
  95th:             487.6 ms     487.1 ms     488.2 ms     466.3 ms
 
- Current rate is 10000 requests / second. Enter a new rate or press enter to view status.
+ Current rate is 10000 requests / second. Enter a command (e.g. "rate 100"), "help" for a list of commands, or press enter to view status.
 
  Rate?
  ```
@@ -95,6 +101,16 @@ Package blaster provides the back-end for blast - a tool for load testing and se
 
  Environment variables and command line flags override config file options. Environment variables are upper case and prefixed with "BLAST" e.g. `BLAST_PAYLOAD_TEMPLATE`.
 
+ Comparing runs
+ ==============
+ `blast compare a.json b.json` compares two reports written with `output-format` set to `json` (see
+ `report-file`). The throughput, success fraction and latency percentiles of all requests and each
+ segment are compared, along with each status within them, and changes for the worse beyond the
+ tolerances are flagged as regressions. The `--throughput` (default 0.05), `--success` (default 0.01) and `--latency`
+ (default 0.1) flags set the largest allowed fractional decrease in throughput, decrease in the
+ success fraction and fractional increase in latency. Blast exits with a non-zero status if any
+ regressions are found, so it can be used to gate a deploy.
+
  Templates
  =========
  The `payload-template` and `worker-template` options accept values that are rendered using the Go text/template system. Variables of the form `{{ .name }}` or `{{ "name" }}` are replaced with data.
@@ -0,0 +1,227 @@
+package blaster
+
+import (
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Generator configures a generated data column. See Config.Generate for more details.
+type Generator struct {
+	// Name sets the name of the field.
+	Name string `mapstructure:"name" json:"name"`
+
+	// Type sets the type of the generator: `sequence`, `uuid`, `int`, `float`, `choice` or `timestamp`.
+	Type string `mapstructure:"type" json:"type"`
+
+	// Start sets the first value of a `sequence`. (Default: 0).
+	Start int64 `mapstructure:"start" json:"start"`
+
+	// Step sets the increment of a `sequence`. (Default: 1).
+	Step int64 `mapstructure:"step" json:"step"`
+
+	// Min sets the minimum value of an `int` or `float`.
+	Min float64 `mapstructure:"min" json:"min"`
+
+	// Max sets the maximum value of an `int` or `float`. For `int` the maximum is inclusive.
+	Max float64 `mapstructure:"max" json:"max"`
+
+	// Decimals rounds a `float` to this number of decimal places. (Default: 0 - full precision).
+	Decimals int `mapstructure:"decimals" json:"decimals"`
+
+	// Choices sets the values of a `choice`.
+	Choices []string `mapstructure:"choices" json:"choices"`
+
+	// Weights sets the relative weight of each of the choices, e.g. `[3, 1]` picks the first choice
+	// three times as often as the second. (Default: all choices are equally likely).
+	Weights []float64 `mapstructure:"weights" json:"weights"`
+
+	// Format sets the format of a `timestamp`: a Go time layout, `unix` (seconds) or `unix-ms`
+	// (milliseconds). (Default: RFC3339).
+	Format string `mapstructure:"format" json:"format"`
+}
+
+// maxInt is 2^63, the first float64 that doesn't fit in an int64.
+const maxInt = float64(1 << 63)
+
+type generatorDef struct {
+	name string
+	next func() string
+}
+
+func (g Generator) parse() (generatorDef, error) {
+	if g.Name == "" {
+		return generatorDef{}, errors.New("generate name must be specified")
+	}
+	d := generatorDef{name: g.Name}
+	switch g.Type {
+	case "sequence":
+		step := g.Step
+		if step == 0 {
+			step = 1
+		}
+		value := g.Start - step
+		d.next = func() string {
+			value += step
+			return strconv.FormatInt(value, 10)
+		}
+	case "uuid":
+		d.next = uuid
+	case "int":
+		if g.Min < -maxInt || g.Max >= maxInt {
+			return generatorDef{}, errors.Errorf("generate %s min and max must fit in a 64 bit integer", g.Name)
+		}
+		min, max := int64(g.Min), int64(g.Max)
+		if float64(min) != g.Min || float64(max) != g.Max {
+			return generatorDef{}, errors.Errorf("generate %s min and max must be whole numbers", g.Name)
+		}
+		if min > max {
+			return generatorDef{}, errors.Errorf("generate %s min must not be greater than max", g.Name)
+		}
+		// the subtraction wraps if the range doesn't fit in an int64
+		span := max - min + 1
+		if span <= 0 {
+			return generatorDef{}, errors.Errorf("generate %s range between min and max is too large", g.Name)
+		}
+		d.next = func() string {
+			return strconv.FormatInt(min+mathrand.Int63n(span), 10)
+		}
+	case "float":
+		if g.Min > g.Max {
+			return generatorDef{}, errors.Errorf("generate %s min must not be greater than max", g.Name)
+		}
+		if g.Decimals < 0 {
+			return generatorDef{}, errors.Errorf("generate %s decimals must not be negative", g.Name)
+		}
+		precision := -1
+		if g.Decimals > 0 {
+			precision = g.Decimals
+		}
+		d.next = func() string {
+			return strconv.FormatFloat(g.Min+mathrand.Float64()*(g.Max-g.Min), 'f', precision, 64)
+		}
+	case "choice":
+		if len(g.Choices) == 0 {
+			return generatorDef{}, errors.Errorf("generate %s choices must be specified", g.Name)
+		}
+		cumulative, err := cumulativeWeights(g.Name, len(g.Choices), g.Weights)
+		if err != nil {
+			return generatorDef{}, err
+		}
+		total := cumulative[len(cumulative)-1]
+		d.next = func() string {
+			r := mathrand.Float64() * total
+			for i, c := range cumulative {
+				if r < c {
+					return g.Choices[i]
+				}
+			}
+			// notest
+			return g.Choices[len(g.Choices)-1]
+		}
+	case "timestamp":
+		switch g.Format {
+		case "unix":
+			d.next = func() string { return strconv.FormatInt(time.Now().Unix(), 10) }
+		case "unix-ms":
+			d.next = func() string { return strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10) }
+		default:
+			layout := g.Format
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			d.next = func() string { return time.Now().Format(layout) }
+		}
+	default:
+		return generatorDef{}, errors.Errorf("generate %s type %q not found", g.Name, g.Type)
+	}
+	return d, nil
+}
+
+// resumableGenerators returns true if every generator produces the same values on each run, so the
+// hash of the data is the same when a run is resumed.
+func resumableGenerators(generators []Generator) bool {
+	for _, g := range generators {
+		if g.Type != "sequence" {
+			return false
+		}
+	}
+	return true
+}
+
+// cumulativeWeights returns the running total of the weights of each choice. If weights is empty, all
+// choices have the same weight.
+func cumulativeWeights(name string, count int, weights []float64) ([]float64, error) {
+	if len(weights) == 0 {
+		weights = make([]float64, count)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != count {
+		return nil, errors.Errorf("generate %s must have the same number of weights and choices", name)
+	}
+	cumulative := make([]float64, count)
+	var total float64
+	for i, w := range weights {
+		if w < 0 {
+			return nil, errors.Errorf("generate %s weights must not be negative", name)
+		}
+		total += w
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return nil, errors.Errorf("generate %s weights must not all be zero", name)
+	}
+	return cumulative, nil
+}
+
+// parseGenerators parses the generated columns, which must have unique names.
+func parseGenerators(generators []Generator) ([]generatorDef, error) {
+	var defs []generatorDef
+	names := map[string]bool{}
+	for _, g := range generators {
+		if names[g.Name] {
+			return nil, errors.Errorf("generate %s is specified more than once", g.Name)
+		}
+		names[g.Name] = true
+		d, err := g.parse()
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, d)
+	}
+	return defs, nil
+}
+
+// generateFields adds the generated columns to the fields of an item. Generated columns take the
+// place of data source fields with the same name.
+func (b *Blaster) generateFields(fields map[string]string) map[string]string {
+	if len(b.generators) == 0 {
+		return fields
+	}
+	generated := make(map[string]string, len(fields)+len(b.generators))
+	for k, v := range fields {
+		generated[k] = v
+	}
+	for _, g := range b.generators {
+		generated[g.name] = g.next()
+	}
+	return generated
+}
+
+// uuid returns a random (version 4) uuid.
+func uuid() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		// notest
+		panic(err)
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
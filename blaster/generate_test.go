@@ -0,0 +1,150 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerators(t *testing.T) {
+	next := func(g Generator) func() string {
+		t.Helper()
+		d, err := g.parse()
+		must(t, err)
+		return d.next
+	}
+
+	sequence := next(Generator{Name: "a", Type: "sequence", Start: 10, Step: 5})
+	if a, b := sequence(), sequence(); a != "10" || b != "15" {
+		t.Fatalf("Unexpected sequence: %s, %s", a, b)
+	}
+
+	u := next(Generator{Name: "a", Type: "uuid"})
+	if a, b := u(), u(); a == b || !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(a) {
+		t.Fatalf("Unexpected uuids: %s, %s", a, b)
+	}
+
+	i := next(Generator{Name: "a", Type: "int", Min: 1, Max: 3})
+	found := map[string]bool{}
+	for n := 0; n < 100; n++ {
+		found[i()] = true
+	}
+	if !reflect.DeepEqual(found, map[string]bool{"1": true, "2": true, "3": true}) {
+		t.Fatalf("Unexpected ints: %v", found)
+	}
+
+	f := next(Generator{Name: "a", Type: "float", Min: 1, Max: 2, Decimals: 2})
+	for n := 0; n < 100; n++ {
+		v := f()
+		if !regexp.MustCompile(`^[12]\.\d\d$`).MatchString(v) {
+			t.Fatalf("Unexpected float: %s", v)
+		}
+	}
+
+	c := next(Generator{Name: "a", Type: "choice", Choices: []string{"x", "y", "z"}, Weights: []float64{1, 0, 1}})
+	found = map[string]bool{}
+	for n := 0; n < 100; n++ {
+		found[c()] = true
+	}
+	if !reflect.DeepEqual(found, map[string]bool{"x": true, "z": true}) {
+		t.Fatalf("Unexpected choices: %v", found)
+	}
+
+	before := time.Now().Unix()
+	ts, err := strconv.ParseInt(next(Generator{Name: "a", Type: "timestamp", Format: "unix"})(), 10, 64)
+	must(t, err)
+	if ts < before || ts > time.Now().Unix() {
+		t.Fatalf("Unexpected timestamp: %d", ts)
+	}
+	if _, err := time.Parse("2006-01-02", next(Generator{Name: "a", Type: "timestamp", Format: "2006-01-02"})()); err != nil {
+		t.Fatalf("Unexpected timestamp: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339, next(Generator{Name: "a", Type: "timestamp"})()); err != nil {
+		t.Fatalf("Unexpected timestamp: %v", err)
+	}
+}
+
+func TestGeneratorsError(t *testing.T) {
+	tests := map[string][]Generator{
+		"no name":        {{Type: "uuid"}},
+		"duplicate name": {{Name: "a", Type: "uuid"}, {Name: "a", Type: "uuid"}},
+		"type":           {{Name: "a", Type: "b"}},
+		"int range":      {{Name: "a", Type: "int", Min: 2, Max: 1}},
+		"int whole":      {{Name: "a", Type: "int", Min: 0.5, Max: 1}},
+		"int overflow":   {{Name: "a", Type: "int", Min: -9e18, Max: 9e18}},
+		"int bounds":     {{Name: "a", Type: "int", Min: 0, Max: 1e19}},
+		"float range":    {{Name: "a", Type: "float", Min: 2, Max: 1}},
+		"decimals":       {{Name: "a", Type: "float", Decimals: -1}},
+		"no choices":     {{Name: "a", Type: "choice"}},
+		"weights length": {{Name: "a", Type: "choice", Choices: []string{"x"}, Weights: []float64{1, 2}}},
+		"weights sign":   {{Name: "a", Type: "choice", Choices: []string{"x", "y"}, Weights: []float64{1, -1}}},
+		"weights zero":   {{Name: "a", Type: "choice", Choices: []string{"x"}, Weights: []float64{0}}},
+	}
+	for name, generators := range tests {
+		if _, err := parseGenerators(generators); err == nil {
+			t.Fatalf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestGenerateWithData(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Generate = []Generator{{Name: "a", Type: "sequence", Start: 1}}
+	must(t, b.openData(ctx, "a,b\nx,1\ny,2\n", true))
+
+	values, _ := runLogged(t, ctx, b)
+	if expected := []string{"1", "2"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+}
+
+func TestGenerateResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Generate = []Generator{{Name: "a", Type: "sequence"}}
+	b.MaxRequests = 3
+	log := new(bytes.Buffer)
+	values, _ := runLoggedTo(t, ctx, b, log)
+	if expected := []string{"0", "1", "2"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	b = New(ctx, cancel)
+	b.Generate = []Generator{{Name: "a", Type: "sequence"}}
+	b.MaxRequests = 2
+	b.Resume = true
+	must(t, b.LoadLogs(log))
+	values, stats := runLogged(t, ctx, b)
+	if expected := []string{"3", "4"}; !reflect.DeepEqual(values, expected) || stats.Skipped != 3 {
+		t.Fatalf("Unexpected values: %v, skipped %d", values, stats.Skipped)
+	}
+}
+
+func TestGenerateResumeRandom(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	if err := b.Initialise(ctx, Config{Resume: true, Generate: []Generator{{Name: "a", Type: "uuid"}}}); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	b.Generate = []Generator{{Name: "a", Type: "sequence"}, {Name: "b", Type: "timestamp"}}
+	b.Resume = true
+	b.Workers = 1
+	b.SetWorker(new(LoggingWorker).NewSuccess)
+	b.SetLog(new(bytes.Buffer))
+	defer b.Exit()
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "only sequence") {
+			t.Fatalf("Unexpected panic: %v", r)
+		}
+	}()
+	b.Start(ctx)
+}
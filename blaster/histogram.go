@@ -0,0 +1,161 @@
+package blaster
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// histogramSubBits sets the precision of the histogram: values below 2^histogramSubBits are recorded
+// exactly, and larger values in log-linear buckets with 2^(histogramSubBits-1) (1024) sub-buckets per
+// power of two. Each bucket is less than 1/1024 of its values wide, so the error is less than 0.1%.
+const histogramSubBits = 11
+
+const (
+	histogramSubCount = 1 << histogramSubBits
+	histogramHalf     = histogramSubCount / 2
+)
+
+// histogram is a high dynamic range latency histogram. Unlike a sampled reservoir, every value is
+// counted, so the tail percentiles are accurate. Values are recorded in microseconds.
+type histogram struct {
+	sync   sync.RWMutex
+	counts []int64
+	count  int64
+	sum    int64
+	max    int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{}
+}
+
+// histogramIndex returns the bucket index for a value.
+func histogramIndex(v int64) int {
+	if v < histogramSubCount {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - histogramSubBits
+	return histogramSubCount + (shift-1)*histogramHalf + int(v>>uint(shift)) - histogramHalf
+}
+
+// histogramValue returns the highest value that would be recorded in a bucket.
+func histogramValue(index int) int64 {
+	if index < histogramSubCount {
+		return int64(index)
+	}
+	shift := uint((index-histogramSubCount)/histogramHalf + 1)
+	sub := int64((index-histogramSubCount)%histogramHalf + histogramHalf)
+	return (sub+1)<<shift - 1
+}
+
+// Update records a duration.
+func (h *histogram) Update(d time.Duration) {
+	v := int64(d / time.Microsecond)
+	if v < 0 {
+		v = 0
+	}
+	i := histogramIndex(v)
+	h.sync.Lock()
+	defer h.sync.Unlock()
+	if i >= len(h.counts) {
+		counts := make([]int64, i+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[i]++
+	h.count++
+	h.sum += v
+	if v > h.max {
+		h.max = v
+	}
+}
+
+// Count returns the number of recorded values.
+func (h *histogram) Count() int64 {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	return h.count
+}
+
+// Mean returns the mean of the recorded values.
+func (h *histogram) Mean() time.Duration {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(h.sum/h.count) * time.Microsecond
+}
+
+// Sum returns the sum of the recorded values.
+func (h *histogram) Sum() time.Duration {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	return time.Duration(h.sum) * time.Microsecond
+}
+
+// Max returns the largest recorded value.
+func (h *histogram) Max() time.Duration {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	return time.Duration(h.max) * time.Microsecond
+}
+
+// Percentile returns the value below which p percent of the recorded values fall, e.g.
+// Percentile(99.9).
+func (h *histogram) Percentile(p float64) time.Duration {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	if h.count == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.count)))
+	if target < 1 {
+		target = 1
+	}
+	var total int64
+	for i, c := range h.counts {
+		total += c
+		if total >= target {
+			v := histogramValue(i)
+			if v > h.max {
+				v = h.max
+			}
+			return time.Duration(v) * time.Microsecond
+		}
+	}
+	// notest
+	return time.Duration(h.max) * time.Microsecond
+}
+
+// Percentiles returns the values at each of the percentiles.
+func (h *histogram) Percentiles(ps []float64) []time.Duration {
+	out := make([]time.Duration, len(ps))
+	for i, p := range ps {
+		out[i] = h.Percentile(p)
+	}
+	return out
+}
+
+// Cumulative returns the number of recorded values less than or equal to each of the bounds, which
+// must be in ascending order.
+func (h *histogram) Cumulative(bounds []time.Duration) []int64 {
+	h.sync.RLock()
+	defer h.sync.RUnlock()
+	out := make([]int64, len(bounds))
+	var total int64
+	b := 0
+	for i, c := range h.counts {
+		for b < len(bounds) && histogramValue(i) > int64(bounds[b]/time.Microsecond) {
+			out[b] = total
+			b++
+		}
+		total += c
+	}
+	for ; b < len(bounds); b++ {
+		out[b] = total
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package blaster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram()
+	for i := 1; i <= 10000; i++ {
+		h.Update(time.Duration(i) * time.Millisecond)
+	}
+	if h.Count() != 10000 {
+		t.Fatalf("Unexpected count: %d", h.Count())
+	}
+	if h.Max() != time.Second*10 {
+		t.Fatalf("Unexpected max: %v", h.Max())
+	}
+	if h.Mean() != time.Microsecond*5000500 {
+		t.Fatalf("Unexpected mean: %v", h.Mean())
+	}
+	for p, expected := range map[float64]time.Duration{
+		50:    time.Second * 5,
+		99:    time.Millisecond * 9900,
+		99.9:  time.Millisecond * 9990,
+		99.99: time.Millisecond * 9999,
+		100:   time.Second * 10,
+	} {
+		actual := h.Percentile(p)
+		if actual < expected || float64(actual) > float64(expected)*1.001 {
+			t.Fatalf("Unexpected p%v: %v (expected %v)", p, actual, expected)
+		}
+	}
+}
+
+func TestHistogramIndex(t *testing.T) {
+	for _, v := range []int64{0, 1, 2047, 2048, 2049, 4095, 4096, 1000000, 123456789} {
+		i := histogramIndex(v)
+		if histogramValue(i) < v || (i > 0 && histogramValue(i-1) >= v) {
+			t.Fatalf("Value %d is not in bucket %d", v, i)
+		}
+	}
+}
+
+func TestHistogramCumulative(t *testing.T) {
+	h := newHistogram()
+	for _, ms := range []int{1, 2, 3, 10, 100} {
+		h.Update(time.Duration(ms) * time.Millisecond)
+	}
+	counts := h.Cumulative([]time.Duration{time.Millisecond * 2, time.Millisecond * 50, time.Second})
+	if counts[0] != 2 || counts[1] != 4 || counts[2] != 5 {
+		t.Fatalf("Unexpected counts: %v", counts)
+	}
+}
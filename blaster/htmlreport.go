@@ -0,0 +1,219 @@
+package blaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// chart sizes in pixels
+const (
+	chartWidth   = 800
+	chartHeight  = 240
+	chartLeft    = 60 // space for the y axis labels
+	chartBottom  = 30 // space for the x axis labels
+	chartTop     = 10
+	chartRight   = 20
+	chartGridMax = 5 // number of horizontal grid lines
+)
+
+var chartColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2"}
+
+type chartSeries struct {
+	name   string
+	values []float64
+}
+
+// WriteHTMLReport writes a self-contained html report of the run to w. It contains rate, throughput,
+// latency and error rate charts, the metrics as printed by Stats.String and the config. The charts
+// need the time-series, which is only recorded if the html report is enabled (see
+// Config.HTMLReport).
+func (b *Blaster) WriteHTMLReport(w io.Writer, stats Stats) error {
+
+	r := b.Report(stats)
+
+	x := make([]float64, len(b.timeseriesRows))
+	values := func() []float64 { return make([]float64, len(x)) }
+	desired, actual, finished, success, failRate, mean := values(), values(), values(), values(), values(), values()
+	percentiles := make([][]float64, len(b.Percentiles))
+	for i := range percentiles {
+		percentiles[i] = values()
+	}
+	for i, row := range b.timeseriesRows {
+		x[i] = row.Time.Sub(r.Start).Seconds()
+		seconds := row.Interval.Seconds()
+		if seconds <= 0 {
+			// notest
+			continue
+		}
+		desired[i] = row.DesiredRate
+		actual[i] = float64(row.Started) / seconds
+		finished[i] = float64(row.Finished) / seconds
+		success[i] = float64(row.Success) / seconds
+		if row.Finished > 0 {
+			failRate[i] = float64(row.Fail) / float64(row.Finished) * 100
+		}
+		mean[i] = milliseconds(row.Mean)
+		for j, p := range b.Percentiles {
+			percentiles[j][i] = milliseconds(row.Percentiles[percentileKey(p)])
+		}
+	}
+
+	latency := []chartSeries{{"mean", mean}}
+	for i, p := range b.Percentiles {
+		latency = append(latency, chartSeries{percentileKey(p), percentiles[i]})
+	}
+
+	data := struct {
+		Report Report
+		Charts []template.HTML
+		Stats  string
+		Config string
+	}{
+		Report: r,
+		Stats:  stats.String(),
+	}
+	if len(x) > 0 {
+		data.Charts = []template.HTML{
+			svgChart("Rate", "requests / second", x, []chartSeries{{"desired", desired}, {"actual", actual}}),
+			svgChart("Throughput", "requests / second", x, []chartSeries{{"finished", finished}, {"success", success}}),
+			svgChart("Latency", "ms", x, latency),
+			svgChart("Error rate", "% failed", x, []chartSeries{{"fail", failRate}}),
+		}
+	}
+	if r.Config != nil {
+		j, err := json.MarshalIndent(r.Config, "", "\t")
+		if err != nil {
+			// notest
+			return errors.WithStack(err)
+		}
+		data.Config = string(j)
+	}
+
+	return errors.WithStack(htmlReportTemplate.Execute(w, data))
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// svgChart renders a line chart as inline svg, so the report has no external dependencies.
+func svgChart(title, unit string, x []float64, series []chartSeries) template.HTML {
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+
+	maxX := x[len(x)-1]
+	if maxX <= 0 {
+		// notest
+		maxX = 1
+	}
+	var maxY float64
+	for _, s := range series {
+		for _, v := range s.values {
+			maxY = math.Max(maxY, v)
+		}
+	}
+	maxY = niceCeiling(maxY)
+
+	px := func(v float64) float64 { return chartLeft + v/maxX*plotWidth }
+	py := func(v float64) float64 { return chartTop + plotHeight - v/maxY*plotHeight }
+
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "<h3>%s <small>(%s)</small></h3>\n", template.HTMLEscapeString(title), template.HTMLEscapeString(unit))
+	fmt.Fprintf(sb, `<svg width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`+"\n", chartWidth, chartHeight, chartWidth, chartHeight)
+	for i := 0; i <= chartGridMax; i++ {
+		v := maxY * float64(i) / chartGridMax
+		fmt.Fprintf(sb, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n", chartLeft, py(v), chartWidth-chartRight, py(v))
+		fmt.Fprintf(sb, `<text x="%d" y="%.1f" text-anchor="end" font-size="11">%s</text>`+"\n", chartLeft-5, py(v)+4, formatFloat(roundFloat(v)))
+	}
+	for i := 0; i <= chartGridMax; i++ {
+		v := maxX * float64(i) / chartGridMax
+		fmt.Fprintf(sb, `<text x="%.1f" y="%d" text-anchor="middle" font-size="11">%ss</text>`+"\n", px(v), chartHeight-10, formatFloat(roundFloat(v)))
+	}
+	for i, s := range series {
+		color := chartColors[i%len(chartColors)]
+		points := make([]string, len(x))
+		for j := range x {
+			points[j] = fmt.Sprintf("%.1f,%.1f", px(x[j]), py(s.values[j]))
+		}
+		fmt.Fprintf(sb, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`+"\n", color, strings.Join(points, " "))
+		fmt.Fprintf(sb, `<text x="%d" y="%d" font-size="11" fill="%s">%s</text>`+"\n", chartLeft+10+i*90, chartTop+12, color, template.HTMLEscapeString(s.name))
+	}
+	sb.WriteString("</svg>\n")
+	return template.HTML(sb.String())
+}
+
+// niceCeiling returns a round number that is at least v, so the axis labels are readable.
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, f := range []float64{1, 2, 2.5, 5, 10} {
+		if f*magnitude >= v {
+			return f * magnitude
+		}
+	}
+	// notest
+	return 10 * magnitude
+}
+
+func roundFloat(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func (b *Blaster) writeHTMLReportFile(stats Stats) error {
+
+	// notest
+
+	if b.htmlReport == "" {
+		return nil
+	}
+	f, err := os.Create(b.htmlReport)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := b.WriteHTMLReport(f, stats); err != nil {
+		return err
+	}
+	return errors.WithStack(f.Close())
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Blast report {{ .Report.Start.Format "2006-01-02 15:04:05" }}</title>
+<style>
+body { font-family: sans-serif; margin: 20px; color: #222; }
+pre { background: #f6f6f6; padding: 10px; overflow-x: auto; }
+small { color: #777; font-weight: normal; }
+td { padding: 2px 10px 2px 0; }
+</style>
+</head>
+<body>
+<h1>Blast report</h1>
+<table>
+<tr><td>Start</td><td>{{ .Report.Start.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+<tr><td>End</td><td>{{ .Report.End.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+{{ if .Report.StopReason }}<tr><td>Stop reason</td><td>{{ .Report.StopReason }}</td></tr>{{ end }}
+{{ if .Report.Error }}<tr><td>Error</td><td>{{ .Report.Error }}</td></tr>{{ end }}
+</table>
+<h2>Charts</h2>
+{{ range .Charts }}{{ . }}{{ else }}<p>No time-series was recorded.</p>{{ end }}
+<h2>Metrics</h2>
+<pre>{{ .Stats }}</pre>
+{{ if .Config }}<h2>Config</h2>
+<pre>{{ .Config }}</pre>
+{{ end }}</body>
+</html>
+`))
@@ -0,0 +1,57 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHTMLReport(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	must(t, b.Initialise(ctx, Config{
+		Rate:               200,
+		Workers:            2,
+		Headers:            []string{"head"},
+		HTMLReport:         "report.html", // only written by Command
+		TimeseriesInterval: "20ms",
+		Percentiles:        []float64{50, 99},
+	}))
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+	b.SetData(strings.NewReader(strings.Repeat("a\n", 20)))
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if len(b.timeseriesRows) < 2 {
+		t.Fatalf("Expected several time-series rows, got %d", len(b.timeseriesRows))
+	}
+
+	buf := new(bytes.Buffer)
+	must(t, b.WriteHTMLReport(buf, stats))
+	out := buf.String()
+
+	if strings.Count(out, "<svg") != 4 || strings.Count(out, "<polyline") != 8 {
+		t.Fatalf("Unexpected charts:\n%s", out)
+	}
+	for _, expected := range []string{"<h3>Rate", "<h3>Throughput", "<h3>Latency", "<h3>Error rate", ">p99<", "Stop reason</td><td>end of data", "Success:          20", `&#34;html-report&#34;: &#34;report.html&#34;`} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected %q in report:\n%s", expected, out)
+		}
+	}
+
+	b.Exit()
+
+}
+
+func TestNiceCeiling(t *testing.T) {
+	for v, expected := range map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 7: 10, 120: 200, 2400: 2500} {
+		if found := niceCeiling(v); found != expected {
+			t.Fatalf("niceCeiling(%v) = %v, expected %v", v, found, expected)
+		}
+	}
+}
@@ -31,6 +31,12 @@ func (b *Blaster) SetLog(w io.Writer) {
 // WriteLogHeaders writes the log headers to the log writer.
 func (b *Blaster) WriteLogHeaders() error {
 	fields := []string{"hash", "result"}
+	if b.Retry != nil {
+		fields = append(fields, "attempt")
+	}
+	if b.multipleDataFiles() {
+		fields = append(fields, "file")
+	}
 	fields = append(fields, b.LogData...)
 	fields = append(fields, b.LogOutput...)
 	if err := b.logWriter.Write(fields); err != nil {
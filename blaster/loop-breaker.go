@@ -0,0 +1,190 @@
+package blaster
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// breakerBuckets is the number of buckets the circuit breaker window is divided into.
+const breakerBuckets = 10
+
+// minBreakerWindow is the shortest circuit breaker window, so each bucket has a useful duration.
+const minBreakerWindow = time.Millisecond
+
+// CircuitBreaker configures the circuit breaker. See Config.CircuitBreaker for more details.
+type CircuitBreaker struct {
+	// Window sets the duration of the sliding window the fail fraction is measured over, e.g. `10s`. (Default: `10s`, minimum `1ms`).
+	Window string `mapstructure:"window" json:"window"`
+
+	// FailFraction sets the fraction of failed requests in the window that trips the breaker. (Default: 0.5).
+	FailFraction float64 `mapstructure:"fail-fraction" json:"fail-fraction"`
+
+	// MinRequests sets the minimum number of requests in the window before the breaker can trip. (Default: 20).
+	MinRequests int64 `mapstructure:"min-requests" json:"min-requests"`
+
+	// Pause sets how long to pause sending before a probe request is sent, e.g. `5s`. (Default: `5s`).
+	Pause string `mapstructure:"pause" json:"pause"`
+}
+
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+type breakerDef struct {
+	sync.Mutex
+	window       time.Duration
+	failFraction float64
+	minRequests  int64
+	pause        time.Duration
+
+	state   string
+	opened  time.Time // time the breaker was last opened
+	probing bool      // a probe request is in flight
+	trips   int64
+	buckets [breakerBuckets]breakerBucket
+}
+
+type breakerBucket struct {
+	start   time.Time
+	success int64
+	fail    int64
+}
+
+func (c CircuitBreaker) parse() (*breakerDef, error) {
+	d := &breakerDef{
+		window:       time.Second * 10,
+		failFraction: c.FailFraction,
+		minRequests:  c.MinRequests,
+		pause:        time.Second * 5,
+		state:        breakerClosed,
+	}
+	if d.failFraction == 0 {
+		d.failFraction = 0.5
+	}
+	if d.minRequests == 0 {
+		d.minRequests = 20
+	}
+	if d.failFraction < 0 || d.failFraction > 1 {
+		return nil, errors.New("circuit-breaker fail-fraction must be between 0 and 1")
+	}
+	if d.minRequests < 0 {
+		return nil, errors.New("circuit-breaker min-requests must not be negative")
+	}
+	var err error
+	if c.Window != "" {
+		if d.window, err = time.ParseDuration(c.Window); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if c.Pause != "" {
+		if d.pause, err = time.ParseDuration(c.Pause); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if d.window <= 0 || d.pause <= 0 {
+		return nil, errors.New("circuit-breaker window and pause must be positive")
+	}
+	if d.window < minBreakerWindow {
+		return nil, errors.Errorf("circuit-breaker window must be at least %s", minBreakerWindow)
+	}
+	return d, nil
+}
+
+// allow is called by the main loop for each tick, and returns true if an item should be sent. When
+// the breaker is half-open, probe is true and only one item is allowed at a time. Only the first
+// payload variant of that item is flagged as the probe: the others are sent, but while the breaker
+// isn't closed their results are ignored by record.
+func (d *breakerDef) allow(now time.Time) (ok, probe bool) {
+	d.Lock()
+	defer d.Unlock()
+	switch d.state {
+	case breakerOpen:
+		if now.Sub(d.opened) < d.pause {
+			return false, false
+		}
+		d.state = breakerHalfOpen
+		d.probing = true
+		return true, true
+	case breakerHalfOpen:
+		if d.probing {
+			return false, false
+		}
+		d.probing = true
+		return true, true
+	}
+	return true, false
+}
+
+// release is called by the main loop if it was allowed to send a probe but had no item to send.
+func (d *breakerDef) release(probe bool) {
+	if d == nil || !probe {
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+	d.probing = false
+}
+
+// record is called by the workers when each item has finished. It returns a message if the state
+// of the breaker changes.
+func (d *breakerDef) record(now time.Time, probe, success bool) string {
+	d.Lock()
+	defer d.Unlock()
+
+	if probe {
+		d.probing = false
+		if success {
+			d.state = breakerClosed
+			d.buckets = [breakerBuckets]breakerBucket{}
+			return "Circuit breaker closed: probe request succeeded. Resuming."
+		}
+		d.state = breakerOpen
+		d.opened = now
+		return ""
+	}
+
+	if d.state != breakerClosed {
+		// results of items that were sent before the breaker opened are ignored.
+		return ""
+	}
+
+	size := d.window / breakerBuckets
+	start := now.Truncate(size)
+	bucket := &d.buckets[(start.UnixNano()/int64(size))%breakerBuckets]
+	if bucket.start != start {
+		*bucket = breakerBucket{start: start}
+	}
+	if success {
+		bucket.success++
+	} else {
+		bucket.fail++
+	}
+
+	var total, fail int64
+	for _, b := range d.buckets {
+		if now.Sub(b.start) < d.window {
+			total += b.success + b.fail
+			fail += b.fail
+		}
+	}
+	if total < d.minRequests || float64(fail)/float64(total) <= d.failFraction {
+		return ""
+	}
+
+	d.state = breakerOpen
+	d.opened = now
+	d.trips++
+	return fmt.Sprintf("Circuit breaker open: %d of the last %d requests failed. Pausing for %s.", fail, total, d.pause)
+}
+
+// status returns the state of the breaker and the number of times it has tripped.
+func (d *breakerDef) status() (string, int64) {
+	d.Lock()
+	defer d.Unlock()
+	return d.state, d.trips
+}
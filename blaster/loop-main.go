@@ -2,14 +2,22 @@ package blaster
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"sync/atomic"
 
 	"encoding/json"
 
+	"time"
+
 	"github.com/leemcloughlin/gofarmhash"
 	"github.com/pkg/errors"
 )
 
+// closedLoopIdleInterval is how long the main loop waits in closed-loop mode when it has nothing to
+// send (e.g. sending is paused), so it doesn't spin on requests from the workers.
+const closedLoopIdleInterval = time.Millisecond * 10
+
 func (b *Blaster) startMainLoop(ctx context.Context) {
 
 	b.mainWait.Add(1)
@@ -17,6 +25,38 @@ func (b *Blaster) startMainLoop(ctx context.Context) {
 	go func() {
 		defer b.mainWait.Done()
 		defer b.println("Exiting main loop")
+
+		var sent int64 // number of items sent to the workers
+		var eof bool   // the end of the data file has been reached, but items may still be retried
+		var probe bool // the next item is a circuit breaker probe
+
+		idle := func() {
+			if b.ClosedLoop {
+				// notest
+				time.Sleep(closedLoopIdleInterval)
+			}
+		}
+
+		// dispatch sends an item to the workers, and returns false if the main loop should exit.
+		dispatch := func(work workDef) bool {
+			work.probe = probe
+			probe = false
+			select {
+			case b.workerChannel <- work:
+			case <-b.workersFinishedChannel:
+				// notest
+				// all workers have exited, so nobody will receive this item.
+				return false
+			}
+			sent++
+			if b.MaxRequests > 0 && sent >= b.MaxRequests {
+				b.println("Max requests reached")
+				b.finish(fmt.Sprintf("max-requests %d reached", b.MaxRequests))
+				return false
+			}
+			return true
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -24,18 +64,58 @@ func (b *Blaster) startMainLoop(ctx context.Context) {
 			case <-b.dataFinishedChannel:
 				// If dataFinishedChannel is closed externally (e.g. in tests), we should return.
 				return
-			case segment := <-b.mainChannel:
+			case tick := <-b.mainChannel:
+				if atomic.LoadInt32(&b.paused) == 1 {
+					// sending is paused, so skip this tick without reading any data.
+					idle()
+					continue
+				}
+				if b.breaker != nil {
+					var ok bool
+					if ok, probe = b.breaker.allow(time.Now()); !ok {
+						// the circuit breaker is open, so skip this tick without reading any data.
+						idle()
+						continue
+					}
+				}
+				// Items that are ready to be retried take the place of fresh items, so retries are
+				// rate limited.
+				if work, ok := b.retries.pop(); ok {
+					work.segment = tick.segment
+					work.intended = tick.intended
+					if !dispatch(work) {
+						return
+					}
+					continue
+				}
+				if eof {
+					if b.retries.done() {
+						b.finish("end of data")
+						return
+					}
+					// nothing to send while waiting for the retry backoff.
+					b.breaker.release(probe)
+					idle()
+					continue
+				}
 				for {
-					var record []string
+					var fields map[string]string
+					var file string
 					if b.dataReader != nil {
 						var err error
-						record, err = b.dataReader.Read()
+						fields, file, err = b.nextData()
 						if err != nil {
 							if err == io.EOF {
 								b.println("Found end of data file")
-								// finish gracefully
-								close(b.dataFinishedChannel)
-								return
+								if b.retries.done() {
+									// finish gracefully
+									b.finish("end of data")
+									return
+								}
+								// wait for failed items to be retried
+								b.breaker.release(probe)
+								eof = true
+								break
 							}
 							// notest
 							b.error(errors.WithStack(err))
@@ -43,15 +123,17 @@ func (b *Blaster) startMainLoop(ctx context.Context) {
 						}
 					}
 
+					fields = b.generateFields(fields)
+
 					skipped := true
 
 					for _, payloadVariantData := range b.PayloadVariants {
 
 						// Build the full data map that will be passed to the worker
 						data := map[string]string{}
-						for i, k := range b.Headers {
-							// Add data from the CSV data source
-							data[k] = record[i]
+						for k, v := range fields {
+							// Add data from the data source
+							data[k] = v
 						}
 						for k, v := range payloadVariantData {
 							// Add data from the payload-variants config
@@ -81,7 +163,12 @@ func (b *Blaster) startMainLoop(ctx context.Context) {
 
 						skipped = false
 
-						b.workerChannel <- workDef{data: data, hash: hash, segment: segment}
+						if b.retryDef != nil {
+							b.retries.add()
+						}
+						if !dispatch(workDef{data: data, hash: hash, file: file, segment: tick.segment, intended: tick.intended, attempt: 1}) {
+							return
+						}
 					}
 					if skipped {
 						// if we've skipped all variants, continue with the next item immediately
@@ -95,7 +182,11 @@ func (b *Blaster) startMainLoop(ctx context.Context) {
 }
 
 type workDef struct {
-	segment int
-	data    map[string]string
-	hash    farmhash.Uint128
+	segment  int
+	intended time.Time
+	attempt  int  // starts at 1, and is incremented each time the item is retried
+	probe    bool // the item is a circuit breaker probe
+	data     map[string]string
+	hash     farmhash.Uint128
+	file     string // data file the item was read from, if the data source is several files
 }
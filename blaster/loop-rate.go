@@ -5,6 +5,7 @@ import (
 	"context"
 	"strconv"
 	"strings"
+	"time"
 
 	"io"
 
@@ -19,10 +20,12 @@ func (b *Blaster) startRateLoop(ctx context.Context) {
 
 	b.mainWait.Add(1)
 
-	readString := func() chan string {
-		c := make(chan string)
-		go func() {
-			reader := bufio.NewReader(b.inputReader)
+	// A single reader is used for the whole loop, so lines that arrive together (e.g. pasted
+	// commands) aren't lost in the buffer of a discarded reader.
+	lines := make(chan string)
+	go func() {
+		reader := bufio.NewReader(b.inputReader)
+		for {
 			text, err := reader.ReadString('\n')
 			if err != nil {
 				if err == io.EOF {
@@ -32,10 +35,15 @@ func (b *Blaster) startRateLoop(ctx context.Context) {
 				b.error(errors.WithStack(err))
 				return
 			}
-			c <- text
-		}()
-		return c
-	}
+			select {
+			case lines <- text:
+			case <-ctx.Done():
+				return
+			case <-b.dataFinishedChannel:
+				return
+			}
+		}
+	}()
 
 	go func() {
 		defer b.mainWait.Done()
@@ -46,21 +54,106 @@ func (b *Blaster) startRateLoop(ctx context.Context) {
 				return
 			case <-b.dataFinishedChannel:
 				return
-			case s := <-readString():
-				s = strings.TrimSpace(s)
-				if s == "" {
-					// notest
-					b.printStatus(false)
-					continue
-				}
-				f, err := strconv.ParseFloat(s, 64)
-				if err != nil {
-					// notest
-					b.error(errors.WithStack(err))
-					return
-				}
-				b.changeRateChannel <- f
+			case s := <-lines:
+				b.command(s)
 			}
 		}
 	}()
 }
+
+const commandHelp = `Commands:
+  rate N          change the rate to N requests / second (or just enter N)
+  workers N       change the number of workers
+  timeout D       change the worker timeout, e.g. "timeout 500ms"
+  pause           pause sending
+  resume          resume sending
+  stop            finish the current items and end the run
+  status          print the status (or just press enter)
+  help            print this message
+`
+
+// command parses and executes a line of input. Invalid input prints a message rather than ending
+// the run.
+func (b *Blaster) command(s string) {
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		// notest
+		b.printStatus(false)
+		return
+	}
+
+	name, args := strings.ToLower(fields[0]), fields[1:]
+
+	// a number on its own changes the rate
+	if _, err := strconv.ParseFloat(name, 64); err == nil {
+		name, args = "rate", fields
+	}
+
+	invalid := func(format string, a ...interface{}) {
+		b.printf(format+" Enter \"help\" for a list of commands.\n", a...)
+	}
+
+	// argument returns the single argument, or false if there isn't exactly one
+	argument := func() (string, bool) {
+		if len(args) != 1 {
+			invalid("The %s command needs one argument.", name)
+			return "", false
+		}
+		return args[0], true
+	}
+
+	switch name {
+	case "rate":
+		arg, ok := argument()
+		if !ok {
+			return
+		}
+		rate, err := strconv.ParseFloat(arg, 64)
+		if err != nil || rate < 0 {
+			invalid("Invalid rate %q.", arg)
+			return
+		}
+		if b.ClosedLoop {
+			invalid("The rate can't be changed in closed-loop mode.")
+			return
+		}
+		b.ChangeRate(rate)
+	case "workers":
+		arg, ok := argument()
+		if !ok {
+			return
+		}
+		count, err := strconv.Atoi(arg)
+		if err != nil {
+			invalid("Invalid number of workers %q.", arg)
+			return
+		}
+		b.ChangeWorkers(count)
+	case "timeout":
+		arg, ok := argument()
+		if !ok {
+			return
+		}
+		timeout, err := time.ParseDuration(arg)
+		if err != nil || timeout <= 0 {
+			invalid("Invalid timeout %q.", arg)
+			return
+		}
+		b.SetTimeout(timeout)
+		b.printf("Timeout is %s.\n", timeout)
+	case "pause":
+		b.SetPaused(true)
+	case "resume":
+		b.SetPaused(false)
+	case "stop":
+		b.println("Stopping...")
+		b.Stop()
+	case "status":
+		b.printStatus(false)
+	case "help":
+		b.printf("\n%s", commandHelp)
+	default:
+		invalid("Unknown command %q.", fields[0])
+	}
+}
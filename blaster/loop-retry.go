@@ -0,0 +1,170 @@
+package blaster
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Retry configures the retry policy. See Config.Retry for more details.
+type Retry struct {
+	// MaxAttempts sets the maximum number of attempts for each item, including the first. (Default: 3).
+	MaxAttempts int `mapstructure:"max-attempts" json:"max-attempts"`
+
+	// Backoff sets the delay before the first retry, e.g. `500ms`. (Default: `100ms`).
+	Backoff string `mapstructure:"backoff" json:"backoff"`
+
+	// MaxBackoff sets the maximum delay between retries, e.g. `30s`. (Default: `10s`).
+	MaxBackoff string `mapstructure:"max-backoff" json:"max-backoff"`
+
+	// Multiplier sets the factor the delay is multiplied by after each retry. (Default: 2).
+	Multiplier float64 `mapstructure:"multiplier" json:"multiplier"`
+
+	// Jitter randomises each delay by up to this fraction, e.g. `0.2` gives delays between 80% and 120% of the backoff. (Default: 0.2).
+	Jitter float64 `mapstructure:"jitter" json:"jitter"`
+
+	// Statuses sets the statuses that should be retried, e.g. `["500", "503"]`. If omitted, all failed items are retried.
+	Statuses []string `mapstructure:"statuses" json:"statuses"`
+}
+
+type retryDef struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	multiplier  float64
+	jitter      float64
+	statuses    map[string]bool
+}
+
+func (r Retry) parse() (retryDef, error) {
+	d := retryDef{
+		maxAttempts: r.MaxAttempts,
+		backoff:     time.Millisecond * 100,
+		maxBackoff:  time.Second * 10,
+		multiplier:  r.Multiplier,
+		jitter:      r.Jitter,
+	}
+	if d.maxAttempts == 0 {
+		d.maxAttempts = 3
+	}
+	if d.multiplier == 0 {
+		d.multiplier = 2
+	}
+	if d.jitter == 0 {
+		d.jitter = 0.2
+	}
+	if d.maxAttempts < 1 {
+		return retryDef{}, errors.New("retry max-attempts must be positive")
+	}
+	if d.multiplier < 1 {
+		return retryDef{}, errors.New("retry multiplier must not be less than 1")
+	}
+	if d.jitter < 0 || d.jitter > 1 {
+		return retryDef{}, errors.New("retry jitter must be between 0 and 1")
+	}
+	var err error
+	if r.Backoff != "" {
+		if d.backoff, err = time.ParseDuration(r.Backoff); err != nil {
+			return retryDef{}, errors.WithStack(err)
+		}
+	}
+	if r.MaxBackoff != "" {
+		if d.maxBackoff, err = time.ParseDuration(r.MaxBackoff); err != nil {
+			return retryDef{}, errors.WithStack(err)
+		}
+	}
+	if d.backoff < 0 || d.maxBackoff < 0 {
+		return retryDef{}, errors.New("retry backoff must not be negative")
+	}
+	if len(r.Statuses) > 0 {
+		d.statuses = map[string]bool{}
+		for _, s := range r.Statuses {
+			d.statuses[s] = true
+		}
+	}
+	return d, nil
+}
+
+// retryable returns true if a failed item with this status should be retried after this attempt.
+func (d retryDef) retryable(attempt int, status string) bool {
+	if attempt >= d.maxAttempts {
+		return false
+	}
+	return d.statuses == nil || d.statuses[status]
+}
+
+// delay returns the backoff before the next attempt.
+func (d retryDef) delay(attempt int) time.Duration {
+	backoff := float64(d.backoff) * math.Pow(d.multiplier, float64(attempt-1))
+	if backoff > float64(d.maxBackoff) {
+		backoff = float64(d.maxBackoff)
+	}
+	backoff = backoff * (1 - d.jitter + 2*d.jitter*rand.Float64())
+	return time.Duration(backoff)
+}
+
+// retryQueue holds failed items that are ready to be retried. The main loop sends these to the
+// workers in place of fresh items, so retries are rate limited.
+type retryQueue struct {
+	sync.Mutex
+	ready []workDef
+	// outstanding is the number of items that have been sent to the workers (or are waiting to be
+	// retried) but haven't yet reached a final result.
+	outstanding int
+}
+
+// add registers a fresh item sent to the workers.
+func (q *retryQueue) add() {
+	q.Lock()
+	defer q.Unlock()
+	q.outstanding++
+}
+
+// resolve registers an item that has reached a final result.
+func (q *retryQueue) resolve() {
+	q.Lock()
+	defer q.Unlock()
+	q.outstanding--
+}
+
+// push adds an item that's ready to be retried.
+func (q *retryQueue) push(work workDef) {
+	q.Lock()
+	defer q.Unlock()
+	q.ready = append(q.ready, work)
+}
+
+// pop returns the oldest item that's ready to be retried.
+func (q *retryQueue) pop() (workDef, bool) {
+	q.Lock()
+	defer q.Unlock()
+	if len(q.ready) == 0 {
+		return workDef{}, false
+	}
+	work := q.ready[0]
+	q.ready = q.ready[1:]
+	return work, true
+}
+
+// done returns true if no items are waiting to be retried or might still need to be retried.
+func (q *retryQueue) done() bool {
+	q.Lock()
+	defer q.Unlock()
+	return q.outstanding == 0 && len(q.ready) == 0
+}
+
+// retry schedules a failed item to be retried after the backoff, and returns true. If the item
+// shouldn't be retried, it returns false.
+func (b *Blaster) retry(work workDef, status string) bool {
+	if b.retryDef == nil || !b.retryDef.retryable(work.attempt, status) {
+		return false
+	}
+	work.attempt++
+	time.AfterFunc(b.retryDef.delay(work.attempt-1), func() {
+		b.retries.push(work)
+	})
+	return true
+}
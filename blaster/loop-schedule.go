@@ -0,0 +1,152 @@
+package blaster
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// scheduleResolution is the interval between rate adjustments during a linear stage.
+const scheduleResolution = time.Millisecond * 100
+
+// RateStage is a stage in the rate schedule. See Config.RateSchedule for more details.
+type RateStage struct {
+	// Rate sets the target rate of this stage in requests per second.
+	Rate float64 `mapstructure:"rate" json:"rate"`
+
+	// Duration sets the duration of this stage, e.g. `30s` or `5m`.
+	Duration string `mapstructure:"duration" json:"duration"`
+
+	// Shape sets the shape of this stage: `step` (the default) jumps straight to the target rate, and `linear` ramps from the previous rate to the target rate over the duration of the stage.
+	Shape string `mapstructure:"shape" json:"shape"`
+}
+
+func (s RateStage) validate() (time.Duration, error) {
+	if s.Rate < 0 {
+		return 0, errors.New("rate-schedule rate must not be negative")
+	}
+	switch s.Shape {
+	case "", "step", "linear":
+	default:
+		return 0, errors.Errorf("rate-schedule shape %q not found", s.Shape)
+	}
+	d, err := time.ParseDuration(s.Duration)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if d <= 0 {
+		return 0, errors.New("rate-schedule duration must be positive")
+	}
+	return d, nil
+}
+
+func (s RateStage) linear() bool {
+	return s.Shape == "linear"
+}
+
+// initialRate returns the rate the ticker should start with, and the desired rate of the first
+// segment.
+func (b *Blaster) initialRate() (rate, desired float64) {
+	if b.ClosedLoop {
+		return 0, 0
+	}
+	if b.Search != nil {
+		def, _ := b.Search.parse(b.Rate) // already validated in Start
+		return def.start, def.start
+	}
+	if len(b.RateSchedule) == 0 {
+		return b.Rate, b.Rate
+	}
+	first := b.RateSchedule[0]
+	if first.linear() {
+		// a linear first stage ramps from the configured rate.
+		return b.Rate, first.Rate
+	}
+	return first.Rate, first.Rate
+}
+
+func (b *Blaster) startScheduleLoop(ctx context.Context) {
+
+	if len(b.RateSchedule) == 0 {
+		return
+	}
+
+	// the ticker loop writes the rate, so read the initial rate before starting the goroutine.
+	from := b.Rate
+
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting schedule loop")
+
+		// send returns false if we should exit
+		send := func(change rateChange) bool {
+			select {
+			case b.scheduleChannel <- change:
+				return true
+			case <-ctx.Done():
+				return false
+			case <-b.dataFinishedChannel:
+				return false
+			}
+		}
+
+		// wait returns false if we should exit
+		wait := func(d time.Duration) bool {
+			select {
+			case <-time.After(d):
+				return true
+			case <-ctx.Done():
+				return false
+			case <-b.dataFinishedChannel:
+				return false
+			}
+		}
+
+		for i, stage := range b.RateSchedule {
+			duration, _ := stage.validate() // already validated in Start
+
+			// The first segment is opened in start (see initialRate), so we only open a new
+			// segment for subsequent stages.
+			if i > 0 {
+				rate := stage.Rate
+				if stage.linear() {
+					rate = from
+				}
+				if !send(rateChange{rate: rate, segment: true, desired: stage.Rate}) {
+					return
+				}
+			}
+
+			if !stage.linear() {
+				if !wait(duration) {
+					return
+				}
+				from = stage.Rate
+				continue
+			}
+
+			start := time.Now()
+			for {
+				elapsed := time.Since(start)
+				if elapsed >= duration {
+					break
+				}
+				rate := from + (stage.Rate-from)*(float64(elapsed)/float64(duration))
+				if !send(rateChange{rate: rate}) {
+					return
+				}
+				if !wait(scheduleResolution) {
+					return
+				}
+			}
+			if !send(rateChange{rate: stage.Rate}) {
+				return
+			}
+			from = stage.Rate
+		}
+		b.printf("Rate schedule finished. Holding at %.0f requests / second.\n", from)
+	}()
+}
@@ -0,0 +1,166 @@
+package blaster
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Search configures the capacity search mode. See Config.Search for more details.
+type Search struct {
+	// Start sets the rate of the first segment. (Default: the rate option).
+	Start float64 `mapstructure:"start" json:"start"`
+
+	// Factor sets the multiplier used to raise the rate until a threshold is crossed. (Default: 2).
+	Factor float64 `mapstructure:"factor" json:"factor"`
+
+	// Duration sets the duration of each segment, e.g. `30s`.
+	Duration string `mapstructure:"duration" json:"duration"`
+
+	// FailFraction sets the highest acceptable fraction of failed requests in a segment, e.g. `0.01`. (Default: 0.01).
+	FailFraction float64 `mapstructure:"fail-fraction" json:"fail-fraction"`
+
+	// P95 sets the highest acceptable 95th percentile latency in a segment, e.g. `200ms`. If omitted, latency is not checked.
+	P95 string `mapstructure:"p95" json:"p95"`
+
+	// Precision sets the relative precision of the result: the binary search stops when the gap between the highest passing rate and the lowest failing rate is less than this fraction of the failing rate. (Default: 0.05).
+	Precision float64 `mapstructure:"precision" json:"precision"`
+}
+
+// searchFloor is the fraction of the start rate below which the search gives up: if every rate down to
+// this has failed, no sustainable rate is found.
+const searchFloor = 0.01
+
+type searchDef struct {
+	start        float64
+	factor       float64
+	duration     time.Duration
+	failFraction float64
+	p95          time.Duration
+	precision    float64
+}
+
+func (s Search) parse(rate float64) (searchDef, error) {
+	d := searchDef{
+		start:        s.Start,
+		factor:       s.Factor,
+		failFraction: s.FailFraction,
+		precision:    s.Precision,
+	}
+	if d.start == 0 {
+		d.start = rate
+	}
+	if d.factor == 0 {
+		d.factor = 2
+	}
+	if d.failFraction == 0 {
+		d.failFraction = 0.01
+	}
+	if d.precision == 0 {
+		d.precision = 0.05
+	}
+	if d.start <= 0 {
+		return searchDef{}, errors.New("search start must be positive")
+	}
+	if d.factor <= 1 {
+		return searchDef{}, errors.New("search factor must be greater than 1")
+	}
+	var err error
+	if d.duration, err = time.ParseDuration(s.Duration); err != nil {
+		return searchDef{}, errors.WithStack(err)
+	}
+	if d.duration <= 0 {
+		return searchDef{}, errors.New("search duration must be positive")
+	}
+	if s.P95 != "" {
+		if d.p95, err = time.ParseDuration(s.P95); err != nil {
+			return searchDef{}, errors.WithStack(err)
+		}
+	}
+	return d, nil
+}
+
+// passed returns true if the segment is within the thresholds.
+func (d searchDef) passed(seg *Segment) bool {
+	if seg.Summary.Finished == 0 {
+		return false
+	}
+	if float64(seg.Summary.Fail)/float64(seg.Summary.Finished) > d.failFraction {
+		return false
+	}
+	if d.p95 > 0 && seg.Summary.NinetyFifth > d.p95 {
+		return false
+	}
+	if seg.ActualRate < seg.DesiredRate*0.9 {
+		// If we can't achieve the desired rate (e.g. all the workers are busy), the rate isn't
+		// sustainable.
+		return false
+	}
+	return true
+}
+
+func (b *Blaster) startSearchLoop(ctx context.Context) {
+
+	if b.Search == nil {
+		return
+	}
+
+	def, _ := b.Search.parse(b.Rate) // already validated in Start
+
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting search loop")
+
+		// lower is the highest passing rate, and upper is the lowest failing rate (or zero if no
+		// rate has failed yet).
+		var lower, upper float64
+
+		rate := def.start
+		for {
+			select {
+			case <-time.After(def.duration):
+			case <-ctx.Done():
+				return
+			case <-b.dataFinishedChannel:
+				return
+			}
+
+			stats := b.Stats()
+			if def.passed(stats.Segments[len(stats.Segments)-1]) {
+				lower = rate
+			} else {
+				upper = rate
+			}
+
+			if upper == 0 {
+				rate = rate * def.factor
+			} else if lower == 0 && upper < def.start*searchFloor {
+				// every rate has failed
+				break
+			} else if (upper-lower)/upper < def.precision {
+				break
+			} else {
+				rate = (lower + upper) / 2
+			}
+
+			select {
+			case b.changeRateChannel <- rate:
+			case <-ctx.Done():
+				return
+			case <-b.dataFinishedChannel:
+				return
+			}
+		}
+
+		b.metrics.setSustainableRate(lower)
+		if lower == 0 {
+			b.println("Search finished. No sustainable rate found.")
+		} else {
+			b.printf("Search finished. Highest sustainable rate is %.0f requests / second.\n", lower)
+		}
+		b.finish("search finished")
+	}()
+}
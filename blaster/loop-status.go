@@ -59,8 +59,17 @@ func (b *Blaster) printRatePrompt() {
 		return
 	}
 
+	if b.ClosedLoop {
+		b.printf(`
+Running in closed-loop mode with %d workers. Enter a command (e.g. "workers 20"), "help" for a list of commands, or press enter to view status.
+`,
+			b.Workers,
+		)
+		return
+	}
+
 	b.printf(`
-Current rate is %.0f requests / second. Enter a new rate or press enter to view status.
+Current rate is %.0f requests / second. Enter a command (e.g. "rate 100"), "help" for a list of commands, or press enter to view status.
 
 Rate?
 `,
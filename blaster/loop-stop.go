@@ -0,0 +1,56 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// minFailFractionSample is the number of requests that must finish before max-fail-fraction is
+// checked.
+const minFailFractionSample = 100
+
+// startStopLoop ends the run when the duration is reached.
+func (b *Blaster) startStopLoop(ctx context.Context) {
+
+	if b.Duration == 0 {
+		return
+	}
+
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting stop loop")
+		timer := time.NewTimer(b.Duration)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-b.dataFinishedChannel:
+		case <-timer.C:
+			b.println("Duration reached")
+			b.finish(fmt.Sprintf("duration %s reached", b.Duration))
+		}
+	}()
+}
+
+// checkFailures ends the run if max-failures or max-fail-fraction has been reached. It's called by
+// the workers after each request has finished.
+func (b *Blaster) checkFailures() {
+
+	if b.MaxFailures == 0 && b.MaxFailFraction == 0 {
+		return
+	}
+
+	fail, finished := b.metrics.failures()
+
+	if b.MaxFailures > 0 && fail >= b.MaxFailures {
+		b.finish(fmt.Sprintf("max-failures %d reached", b.MaxFailures))
+		return
+	}
+
+	if b.MaxFailFraction > 0 && finished >= minFailFractionSample && float64(fail)/float64(finished) > b.MaxFailFraction {
+		b.finish(fmt.Sprintf("max-fail-fraction %v exceeded", b.MaxFailFraction))
+		return
+	}
+}
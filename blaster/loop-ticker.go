@@ -5,53 +5,125 @@ import (
 	"time"
 )
 
+// maxTickerLag is how far the ticker may fall behind before it stops trying to catch up.
+const maxTickerLag = time.Second
+
+// maxBacklog is the number of ticks that may be queued in correct-latency mode. Further ticks are
+// dropped (and counted as missed), so a sustained overload doesn't grow memory without limit.
+const maxBacklog = 100000
+
 func (b *Blaster) startTickerLoop(ctx context.Context) {
 
 	b.mainWait.Add(1)
 
-	var ticker *time.Ticker
+	// We use a timer rather than a time.Ticker so the rate can be adjusted (e.g. during a linear
+	// ramp in the rate schedule) without resetting the phase of the next tick, and so the interval
+	// between ticks can be drawn from the arrival distribution.
+	var timer *time.Timer
+	var tick <-chan time.Time
+	var last, next time.Time // scheduled times of the previous and next ticks
 
-	updateTicker := func() {
-		if b.Rate == 0 {
-			ticker = &time.Ticker{} // empty *time.Ticker will have nil C, so block forever.
-			return
-		}
-		ticksPerSecond := b.Rate/float64(len(b.PayloadVariants))
+	arrival := arrivals[b.Arrival]
+	if arrival == nil {
+		arrival = arrivals["constant"]
+	}
+
+	interval := func() time.Duration {
+		ticksPerSecond := b.Rate / float64(len(b.PayloadVariants))
 		ticksPerMs := ticksPerSecond / 1000.0
 		ticksPerUs := ticksPerMs / 1000.0
 		ticksPerNs := ticksPerUs / 1000.0
 		nsPerTick := 1.0 / ticksPerNs
-		
-		ticker = time.NewTicker(time.Nanosecond * time.Duration(nsPerTick))
+		return time.Nanosecond * time.Duration(nsPerTick*arrival())
+	}
+
+	updateTimer := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		if b.Rate == 0 || b.ClosedLoop {
+			// In closed-loop mode, workers request items themselves so we never tick.
+			timer = nil
+			tick = nil // nil channel will block forever.
+			return
+		}
+		now := time.Now()
+		if last == (time.Time{}) {
+			last = now
+		}
+		// We schedule from the previous scheduled tick rather than from now, so small delays
+		// don't accumulate and the actual rate converges on the desired rate.
+		next = last.Add(interval())
+		if now.Sub(next) > maxTickerLag {
+			// notest
+			// If we've fallen a long way behind, don't try to catch up.
+			next = now
+		}
+		timer = time.NewTimer(next.Sub(now))
+		tick = timer.C
 	}
 
 	changeRate := func(rate float64) {
 		b.Rate = rate
-		if ticker != nil {
-			ticker.Stop()
-		}
 		b.metrics.addSegment(b.Rate)
-		updateTicker()
+		updateTimer()
 		b.printStatus(false)
 	}
 
-	updateTicker()
+	adjustRate := func(change rateChange) {
+		b.Rate = change.rate
+		if change.segment {
+			b.metrics.addSegment(change.desired)
+		}
+		updateTimer()
+		if change.segment {
+			b.printStatus(false)
+		}
+	}
+
+	updateTimer()
+
+	// In correct-latency mode, ticks that find no idle worker are queued rather than dropped, so
+	// latency can be measured from the intended send time.
+	var backlog []tickDef
+
+	// queue adds a tick to the backlog, or drops it if the backlog is full.
+	queue := func(t tickDef) {
+		if len(backlog) >= maxBacklog {
+			b.metrics.logMiss(t.segment)
+			return
+		}
+		b.metrics.logDelay(t.segment)
+		backlog = append(backlog, t)
+	}
 
 	go func() {
 		defer b.mainWait.Done()
 		defer b.println("Exiting ticker loop")
 		defer func() {
-			if ticker != nil {
-				ticker.Stop()
+			if timer != nil {
+				timer.Stop()
 			}
 		}()
 		for {
 
+			// If there's a backlog of ticks, we should also try to send the oldest one.
+			var backlogChannel chan tickDef
+			var oldest tickDef
+			if len(backlog) > 0 {
+				backlogChannel = b.mainChannel
+				oldest = backlog[0]
+			}
+
 			// First wait for a tick... but we should also wait for an exit signal, data finished
 			// signal or rate change command (we could be waiting forever on rate = 0).
 			select {
-			case <-ticker.C:
-				// continue
+			case <-tick:
+				last = next
+				updateTimer()
+			case backlogChannel <- oldest:
+				backlog = backlog[1:]
+				continue
 			case <-ctx.Done():
 				return
 			case <-b.dataFinishedChannel:
@@ -61,14 +133,24 @@ func (b *Blaster) startTickerLoop(ctx context.Context) {
 				// any more.
 				changeRate(rate)
 				continue
+			case change := <-b.scheduleChannel:
+				adjustRate(change)
+				continue
 			}
 
-			segment := b.metrics.currentSegment()
+			t := tickDef{segment: b.metrics.currentSegment(), intended: last}
+
+			if len(backlog) > 0 {
+				// notest
+				// keep the backlog in order
+				queue(t)
+				continue
+			}
 
 			// Next send on the main channel. The channel won't have a listener if there is no idle
 			// worker. In this case we should continue and log a miss.
 			select {
-			case b.mainChannel <- segment:
+			case b.mainChannel <- t:
 				// if main loop is waiting, send it a message
 			case <-ctx.Done():
 				// notest
@@ -78,9 +160,27 @@ func (b *Blaster) startTickerLoop(ctx context.Context) {
 				return
 			default:
 				// notest
-				// if main loop is busy, skip this tick
+				// if main loop is busy, skip this tick (or queue it in correct-latency mode)
+				if b.CorrectLatency {
+					queue(t)
+				} else {
+					b.metrics.logMiss(t.segment)
+				}
 				continue
 			}
 		}
 	}()
 }
+
+// tickDef is sent by the ticker loop to the main loop for each tick.
+type tickDef struct {
+	segment  int
+	intended time.Time // the time the item should have been sent
+}
+
+// rateChange is sent by the schedule loop to adjust the rate of the ticker.
+type rateChange struct {
+	rate    float64
+	segment bool    // open a new metrics segment
+	desired float64 // desired rate of the new segment
+}
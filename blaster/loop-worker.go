@@ -3,6 +3,7 @@ package blaster
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"time"
 
@@ -12,83 +13,164 @@ import (
 )
 
 func (b *Blaster) startWorkers(ctx context.Context) {
+	atomic.StoreInt64(&b.workers, int64(b.Workers))
 	for i := 0; i < b.Workers; i++ {
-
-		// assign rotated vars from config
-		workerVariantData := map[string]string{}
-		if b.WorkerVariants != nil {
-			for k, v := range b.WorkerVariants[i%len(b.WorkerVariants)] {
-				workerVariantData[k] = v
-			}
+		if err := b.startWorker(ctx); err != nil {
+			// notest
+			b.error(err)
+			return
 		}
+	}
+	b.startWorkersLoop(ctx)
+}
 
-		w := b.workerFunc()
+// startWorkersLoop listens for changes to the number of workers.
+func (b *Blaster) startWorkersLoop(ctx context.Context) {
 
-		if s, ok := w.(Starter); ok {
-			workerSetup, err := renderMap(b.workerRenderer, workerVariantData)
-			if err != nil {
-				// notest
-				b.error(err)
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting workers loop")
+		for {
+			select {
+			case <-ctx.Done():
 				return
-			}
-			if err := s.Start(ctx, workerSetup); err != nil {
-				// notest
-				b.error(errors.WithStack(err))
+			case <-b.dataFinishedChannel:
 				return
-			}
-		}
-
-		b.workerWait.Add(1)
-		go func(index int) {
-			defer b.workerWait.Done()
-			defer func() {
-				if s, ok := w.(Stopper); ok {
-					workerSetup, err := renderMap(b.workerRenderer, workerVariantData)
-					if err != nil {
+			case count := <-b.changeWorkersChannel:
+				if count < 1 {
+					b.println("Must have at least one worker.")
+					continue
+				}
+				for len(b.workerStops) < count {
+					if err := b.startWorker(ctx); err != nil {
 						// notest
 						b.error(err)
 						return
 					}
-					if err := s.Stop(ctx, workerSetup); err != nil {
-						// notest
-						b.error(errors.WithStack(err))
-						return
-					}
 				}
-			}()
+				for len(b.workerStops) > count {
+					// surplus workers finish their current item then exit gracefully
+					last := len(b.workerStops) - 1
+					close(b.workerStops[last])
+					b.workerStops = b.workerStops[:last]
+				}
+				atomic.StoreInt64(&b.workers, int64(count))
+				b.printStatus(false)
+			}
+		}
+	}()
+}
+
+// startWorker creates a worker, calls Start if the worker satisfies Starter, and starts the worker
+// goroutine. Only call this from startWorkers or the workers loop.
+func (b *Blaster) startWorker(ctx context.Context) error {
+
+	index := len(b.workerStops)
+
+	// assign rotated vars from config
+	workerVariantData := map[string]string{}
+	if b.WorkerVariants != nil {
+		for k, v := range b.WorkerVariants[index%len(b.WorkerVariants)] {
+			workerVariantData[k] = v
+		}
+	}
+
+	w := b.workerFunc()
+
+	if s, ok := w.(Starter); ok {
+		workerSetup, err := renderMap(b.workerRenderer, workerVariantData)
+		if err != nil {
+			// notest
+			return err
+		}
+		if err := s.Start(ctx, workerSetup); err != nil {
+			// notest
+			return errors.WithStack(err)
+		}
+	}
+
+	stop := make(chan struct{})
+	b.workerStops = append(b.workerStops, stop)
 
-			for {
-				select {
-				case <-ctx.Done():
+	b.workerWait.Add(1)
+	go func() {
+		defer b.workerWait.Done()
+		defer func() {
+			if s, ok := w.(Stopper); ok {
+				workerSetup, err := renderMap(b.workerRenderer, workerVariantData)
+				if err != nil {
+					// notest
+					b.error(err)
 					return
-				case <-b.dataFinishedChannel:
-					// exit gracefully
+				}
+				if err := s.Stop(ctx, workerSetup); err != nil {
+					// notest
+					b.error(errors.WithStack(err))
 					return
-				case work := <-b.workerChannel:
-					if err := b.send(ctx, w, work); err != nil {
-						// notest
-						b.error(err)
-						return
-					}
-					if b.itemFinishedChannel != nil {
-						// only used in tests
-						b.itemFinishedChannel <- struct{}{}
-					}
 				}
 			}
-		}(i)
-	}
+		}()
+
+		for {
+			// Check stop first, so a surplus worker doesn't take another item: in the select below,
+			// a ready item would be picked at random over the stop signal.
+			select {
+			case <-stop:
+				// the number of workers has been reduced
+				return
+			default:
+			}
+			// In closed-loop mode, an idle worker asks the main loop for the next item as soon
+			// as it's ready, so concurrency is bounded only by the number of workers.
+			var request chan tickDef
+			if b.ClosedLoop {
+				request = b.mainChannel
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.dataFinishedChannel:
+				// exit gracefully
+				return
+			case <-stop:
+				// the number of workers has been reduced
+				return
+			case request <- tickDef{segment: b.metrics.currentSegment()}:
+				// the main loop will send the item on the worker channel
+			case work := <-b.workerChannel:
+				if err := b.send(ctx, w, work); err != nil {
+					// notest
+					b.error(err)
+					return
+				}
+				if b.itemFinishedChannel != nil {
+					// only used in tests
+					b.itemFinishedChannel <- struct{}{}
+				}
+			}
+		}
+	}()
+	return nil
 }
 
 func (b *Blaster) send(ctx context.Context, w Worker, work workDef) error {
 
 	b.metrics.logStart(work.segment)
+	if work.attempt > 1 {
+		b.metrics.logRetry(work.segment)
+	}
 	b.metrics.logBusy(work.segment)
 	b.metrics.busy.Inc(1)
 	defer b.metrics.busy.Dec(1)
 
-	// Record the start time
+	// Record the start time. In correct-latency mode we measure from the time the item should have
+	// been sent, so time spent waiting for an idle worker is included.
 	start := time.Now()
+	if b.CorrectLatency && work.intended != (time.Time{}) {
+		start = work.intended
+	}
 
 	// Render the payload template with the data generated above
 	renderedTemplate, err := renderMap(b.payloadRenderer, work.data)
@@ -97,7 +179,8 @@ func (b *Blaster) send(ctx context.Context, w Worker, work workDef) error {
 	}
 
 	// Create a child context with the selected timeout
-	child, cancel := context.WithTimeout(ctx, b.softTimeout)
+	softTimeout, hardTimeout := b.timeouts()
+	child, cancel := context.WithTimeout(ctx, softTimeout)
 	defer cancel()
 
 	finished := make(chan struct{})
@@ -124,10 +207,10 @@ func (b *Blaster) send(ctx context.Context, w Worker, work workDef) error {
 		select {
 		case <-finished: // notest
 			// Only continue when finished channel is closed - e.g. sending goroutine has exited.
-		case <-time.After(b.hardTimeout):
+		case <-time.After(hardTimeout):
 			hardTimeoutExceeded = true
 		}
-	case <-time.After(b.hardTimeout):
+	case <-time.After(hardTimeout):
 		hardTimeoutExceeded = true
 	}
 
@@ -149,9 +232,27 @@ func (b *Blaster) send(ctx context.Context, w Worker, work workDef) error {
 		val = "(none)"
 	}
 	b.metrics.logFinish(work.segment, val, time.Since(start), success)
+	b.checkFailures()
+
+	if b.breaker != nil {
+		if message := b.breaker.record(time.Now(), work.probe, success); message != "" {
+			b.println(message)
+		}
+	}
+
+	if b.retryDef != nil && (success || !b.retry(work, val)) {
+		// the item has reached a final result
+		b.retries.resolve()
+	}
 
 	if b.logWriter != nil {
 		var fields []string
+		if b.Retry != nil {
+			fields = append(fields, fmt.Sprint(work.attempt))
+		}
+		if b.multipleDataFiles() {
+			fields = append(fields, work.file)
+		}
 		for _, key := range b.LogData {
 			var val string
 			if v, ok := work.data[key]; ok {
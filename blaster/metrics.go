@@ -8,14 +8,17 @@ import (
 )
 
 type metricsDef struct {
-	sync     sync.RWMutex
-	registry metrics.Registry
-	current  int
-	skipped  metrics.Counter
-	busy     metrics.Counter
-	all      *metricsSegment
-	segments []*metricsSegment
-	blaster  *Blaster
+	sync      sync.RWMutex
+	registry  metrics.Registry
+	current   int
+	sustained float64
+	reason    string
+	skipped   metrics.Counter
+	busy      metrics.Counter
+	all       *metricsSegment
+	segments  []*metricsSegment
+	interval  *metricsInterval
+	blaster   *Blaster
 }
 
 func newMetricsDef(b *Blaster) *metricsDef {
@@ -27,6 +30,7 @@ func newMetricsDef(b *Blaster) *metricsDef {
 		blaster:  b,
 	}
 	m.all = m.newMetricsSegment(0)
+	m.interval = newMetricsInterval()
 	return m
 }
 
@@ -41,6 +45,40 @@ func (m *metricsDef) logSkip() {
 	m.skipped.Inc(1)
 }
 
+func (m *metricsDef) setSustainableRate(rate float64) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+	m.sustained = rate
+}
+
+// setStopReason records the reason the run ended. Only the first reason is recorded.
+func (m *metricsDef) setStopReason(reason string) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+	if m.reason == "" {
+		m.reason = reason
+	}
+}
+
+// failures returns the total number of failed and finished requests.
+func (m *metricsDef) failures() (fail, finished int64) {
+	return m.all.total.fail.Count(), m.all.total.finish.Count()
+}
+
+func (m *metricsDef) logMiss(segment int) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+	m.all.missed.Inc(1)
+	m.segments[segment].missed.Inc(1)
+}
+
+func (m *metricsDef) logDelay(segment int) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+	m.all.delayed.Inc(1)
+	m.segments[segment].delayed.Inc(1)
+}
+
 func (m *metricsDef) currentSegment() int {
 	m.sync.RLock()
 	defer m.sync.RUnlock()
@@ -52,6 +90,14 @@ func (m *metricsDef) logStart(segment int) {
 	defer m.sync.Unlock()
 	m.all.logStart()
 	m.segments[segment].logStart()
+	m.interval.start++
+}
+
+func (m *metricsDef) logRetry(segment int) {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+	m.all.total.retry.Inc(1)
+	m.segments[segment].total.retry.Inc(1)
 }
 
 func (m *metricsDef) logFinish(segment int, status string, elapsed time.Duration, success bool) {
@@ -59,6 +105,7 @@ func (m *metricsDef) logFinish(segment int, status string, elapsed time.Duration
 	defer m.sync.Unlock()
 	m.all.logFinish(status, elapsed, success)
 	m.segments[segment].logFinish(status, elapsed, success)
+	m.interval.logFinish(elapsed, success)
 }
 
 func (m *metricsDef) addSegment(rate float64) {
@@ -74,32 +121,37 @@ func (m *metricsDef) addSegment(rate float64) {
 func (m *metricsDef) newMetricsItem() *metricsItem {
 	return &metricsItem{
 		start:   metrics.NewRegisteredCounter("start", m.registry),
-		finish:  metrics.NewRegisteredTimer("finish", m.registry),
+		finish:  newHistogram(),
 		success: metrics.NewRegisteredCounter("success", m.registry),
 		fail:    metrics.NewRegisteredCounter("fail", m.registry),
+		retry:   metrics.NewRegisteredCounter("retry", m.registry),
 	}
 }
 
 func (m *metricsDef) newMetricsSegment(rate float64) *metricsSegment {
 	return &metricsSegment{
-		def:    m,
-		rate:   rate,
-		total:  m.newMetricsItem(),
-		status: map[string]*metricsItem{},
-		busy:   metrics.NewRegisteredHistogram("busy", m.registry, metrics.NewExpDecaySample(1028, 0.015)),
-		start:  time.Now(),
+		def:     m,
+		rate:    rate,
+		total:   m.newMetricsItem(),
+		status:  map[string]*metricsItem{},
+		busy:    metrics.NewRegisteredHistogram("busy", m.registry, metrics.NewExpDecaySample(1028, 0.015)),
+		missed:  metrics.NewRegisteredCounter("missed", m.registry),
+		delayed: metrics.NewRegisteredCounter("delayed", m.registry),
+		start:   time.Now(),
 	}
 }
 
 type metricsSegment struct {
-	sync   sync.RWMutex
-	def    *metricsDef
-	rate   float64
-	busy   metrics.Histogram
-	total  *metricsItem
-	status map[string]*metricsItem
-	start  time.Time
-	end    time.Time
+	sync    sync.RWMutex
+	def     *metricsDef
+	rate    float64
+	busy    metrics.Histogram
+	missed  metrics.Counter // ticks that were dropped
+	delayed metrics.Counter // ticks that were queued in correct-latency mode and sent late
+	total   *metricsItem
+	status  map[string]*metricsItem
+	start   time.Time
+	end     time.Time
 }
 
 func (m *metricsSegment) duration() time.Duration {
@@ -135,7 +187,8 @@ func (m *metricsSegment) logFinish(status string, elapsed time.Duration, success
 
 type metricsItem struct {
 	start   metrics.Counter
-	finish  metrics.Timer
+	finish  *histogram
 	success metrics.Counter
 	fail    metrics.Counter
+	retry   metrics.Counter
 }
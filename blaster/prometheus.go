@@ -0,0 +1,165 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prometheusBuckets are the upper bounds of the latency histogram buckets.
+var prometheusBuckets = []time.Duration{
+	time.Millisecond,
+	time.Microsecond * 2500,
+	time.Millisecond * 5,
+	time.Millisecond * 10,
+	time.Millisecond * 25,
+	time.Millisecond * 50,
+	time.Millisecond * 100,
+	time.Millisecond * 250,
+	time.Millisecond * 500,
+	time.Second,
+	time.Millisecond * 2500,
+	time.Second * 5,
+	time.Second * 10,
+}
+
+// MetricsHandler returns a http.Handler that serves the metrics in Prometheus exposition format.
+func (b *Blaster) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		b.metrics.writePrometheus(buf)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(buf.Bytes())
+	})
+}
+
+// startMetricsServer serves the metrics on the listener opened by listen.
+func (b *Blaster) startMetricsServer(ctx context.Context) {
+
+	listener := b.metricsListener
+	if listener == nil {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", b.MetricsHandler())
+	server := &http.Server{Handler: mux}
+
+	b.mainWait.Add(1)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting metrics server")
+		go server.Serve(listener)
+		// don't react to ctx.Done() here so the final metrics can be scraped until the workers have
+		// finished.
+		<-b.workersFinishedChannel
+		server.Close()
+	}()
+}
+
+func (m *metricsDef) writePrometheus(w io.Writer) {
+	m.sync.RLock()
+	defer m.sync.RUnlock()
+
+	family := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	var statuses []string
+	for status := range m.all.status {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	// each calls f for each segment and status that has results
+	each := func(f func(labels string, item *metricsItem)) {
+		for i, seg := range m.segments {
+			for _, status := range statuses {
+				if item, ok := seg.status[status]; ok {
+					f(fmt.Sprintf(`segment="%d",status="%s"`, i, escapeLabel(status)), item)
+				}
+			}
+		}
+	}
+
+	family("blast_requests_started_total", "counter", "Requests started.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_requests_started_total{segment=\"%d\"} %d\n", i, seg.total.start.Count())
+	}
+
+	family("blast_requests_retried_total", "counter", "Requests that were retries of failed items.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_requests_retried_total{segment=\"%d\"} %d\n", i, seg.total.retry.Count())
+	}
+
+	family("blast_requests_finished_total", "counter", "Requests finished.")
+	each(func(labels string, item *metricsItem) {
+		fmt.Fprintf(w, "blast_requests_finished_total{%s} %d\n", labels, item.finish.Count())
+	})
+
+	family("blast_requests_success_total", "counter", "Requests that succeeded.")
+	each(func(labels string, item *metricsItem) {
+		fmt.Fprintf(w, "blast_requests_success_total{%s} %d\n", labels, item.success.Count())
+	})
+
+	family("blast_requests_fail_total", "counter", "Requests that failed.")
+	each(func(labels string, item *metricsItem) {
+		fmt.Fprintf(w, "blast_requests_fail_total{%s} %d\n", labels, item.fail.Count())
+	})
+
+	family("blast_request_duration_seconds", "histogram", "Request latency.")
+	each(func(labels string, item *metricsItem) {
+		for i, count := range item.finish.Cumulative(prometheusBuckets) {
+			fmt.Fprintf(w, "blast_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(prometheusBuckets[i].Seconds()), count)
+		}
+		fmt.Fprintf(w, "blast_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, item.finish.Count())
+		fmt.Fprintf(w, "blast_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(item.finish.Sum().Seconds()))
+		fmt.Fprintf(w, "blast_request_duration_seconds_count{%s} %d\n", labels, item.finish.Count())
+	})
+
+	family("blast_missed_ticks_total", "counter", "Ticks that were dropped because no worker was idle.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_missed_ticks_total{segment=\"%d\"} %d\n", i, seg.missed.Count())
+	}
+
+	family("blast_delayed_ticks_total", "counter", "Ticks that were queued in correct-latency mode because no worker was idle.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_delayed_ticks_total{segment=\"%d\"} %d\n", i, seg.delayed.Count())
+	}
+
+	family("blast_rate_desired", "gauge", "Desired rate in requests per second.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_rate_desired{segment=\"%d\"} %s\n", i, formatFloat(seg.rate))
+	}
+
+	family("blast_rate_actual", "gauge", "Actual rate in requests per second.")
+	for i, seg := range m.segments {
+		fmt.Fprintf(w, "blast_rate_actual{segment=\"%d\"} %s\n", i, formatFloat(float64(seg.total.start.Count())/seg.duration().Seconds()))
+	}
+
+	family("blast_workers_busy", "gauge", "Workers currently sending a request.")
+	fmt.Fprintf(w, "blast_workers_busy %d\n", m.busy.Count())
+
+	family("blast_workers", "gauge", "Number of workers.")
+	fmt.Fprintf(w, "blast_workers %d\n", m.blaster.currentWorkers())
+
+	family("blast_items_skipped_total", "counter", "Items skipped because they succeeded in a previous run.")
+	fmt.Fprintf(w, "blast_items_skipped_total %d\n", m.skipped.Count())
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
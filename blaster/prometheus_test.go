@@ -0,0 +1,62 @@
+package blaster
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPrometheus(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.itemFinishedChannel = make(chan struct{})
+	b.MetricsAddr = "127.0.0.1:0"
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	for i := 0; i < 3; i++ {
+		b.mainChannel <- tickDef{}
+		<-b.itemFinishedChannel
+	}
+
+	resp, err := http.Get("http://" + b.metricsListener.Addr().String() + "/metrics")
+	must(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	must(t, err)
+	resp.Body.Close()
+
+	for _, expected := range []string{
+		"# TYPE blast_requests_started_total counter",
+		`blast_requests_started_total{segment="0"} 3`,
+		`blast_requests_finished_total{segment="0",status="[success]"} 3`,
+		`blast_requests_success_total{segment="0",status="[success]"} 3`,
+		`blast_requests_fail_total{segment="0",status="[success]"} 0`,
+		"# TYPE blast_request_duration_seconds histogram",
+		`blast_request_duration_seconds_bucket{segment="0",status="[success]",le="+Inf"} 3`,
+		`blast_request_duration_seconds_count{segment="0",status="[success]"} 3`,
+		`blast_rate_desired{segment="0"} 0`,
+		"blast_workers_busy 0",
+		"blast_workers 10",
+		"blast_items_skipped_total 0",
+	} {
+		if !strings.Contains(string(body), expected+"\n") {
+			t.Fatalf("Expected %q in metrics:\n%s", expected, body)
+		}
+	}
+
+	close(b.dataFinishedChannel)
+	must(t, <-finished)
+
+	b.Exit()
+
+}
@@ -3,7 +3,13 @@ package blaster
 import (
 	"bytes"
 	"context"
+	"io"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 func TestChangeRate(t *testing.T) {
@@ -72,3 +78,383 @@ func TestInput(t *testing.T) {
 	b.Exit()
 
 }
+
+func TestRateSchedule(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0
+	b.RateSchedule = []RateStage{
+		{Rate: 100, Duration: "50ms"},
+		{Rate: 200, Duration: "100ms", Shape: "linear"},
+		{Rate: 0, Duration: "50ms"},
+	}
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan struct{})
+	go func() {
+		must(t, b.start(ctx))
+		close(finished)
+	}()
+
+	<-time.After(time.Millisecond * 300)
+
+	stats := b.Stats()
+	if len(stats.Segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %d", len(stats.Segments))
+	}
+	for i, desired := range []float64{100, 200, 0} {
+		if stats.Segments[i].DesiredRate != desired {
+			t.Fatalf("Segment %d: expected desired rate %v, got %v", i, desired, stats.Segments[i].DesiredRate)
+		}
+	}
+	if stats.Segments[0].Summary.Started == 0 || stats.Segments[1].Summary.Started == 0 {
+		t.Fatal("Expected items to be started in the first two segments")
+	}
+	if b.Rate != 0 {
+		t.Fatalf("Expected rate to be held at 0, got %v", b.Rate)
+	}
+
+	b.Exit()
+	<-finished
+
+}
+
+func TestMissedTicks(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 200
+	b.Workers = 1
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewHang(50))
+
+	finished := make(chan struct{})
+	go func() {
+		must(t, b.start(ctx))
+		close(finished)
+	}()
+
+	<-time.After(time.Millisecond * 200)
+
+	stats := b.Stats()
+	if stats.All.Missed == 0 || stats.Segments[0].Missed != stats.All.Missed {
+		t.Fatalf("Expected missed ticks, got %d (all) and %d (segment)", stats.All.Missed, stats.Segments[0].Missed)
+	}
+
+	b.Exit()
+	<-finished
+
+}
+
+func TestCorrectLatency(t *testing.T) {
+
+	run := func(correct bool) Stats {
+		ctx, cancel := context.WithCancel(context.Background())
+		b := New(ctx, cancel)
+		b.Rate = 100
+		b.Workers = 1
+		b.CorrectLatency = correct
+
+		workerLog := new(LoggingWorker)
+		b.SetWorker(workerLog.NewHang(30))
+
+		finished := make(chan struct{})
+		go func() {
+			must(t, b.start(ctx))
+			close(finished)
+		}()
+
+		<-time.After(time.Millisecond * 300)
+		stats := b.Stats()
+		b.Exit()
+		<-finished
+		return stats
+	}
+
+	uncorrected := run(false)
+	if uncorrected.All.Summary.NinetyFifth > time.Millisecond*60 {
+		t.Fatalf("Expected uncorrected latency close to 30ms, got %v", uncorrected.All.Summary.NinetyFifth)
+	}
+	if uncorrected.All.Missed == 0 || uncorrected.All.Delayed != 0 {
+		t.Fatalf("Expected missed ticks only, got %d missed and %d delayed", uncorrected.All.Missed, uncorrected.All.Delayed)
+	}
+
+	corrected := run(true)
+	if corrected.All.Summary.NinetyFifth < time.Millisecond*60 {
+		t.Fatalf("Expected corrected latency to include time waiting for a worker, got %v", corrected.All.Summary.NinetyFifth)
+	}
+	if corrected.All.Missed != 0 || corrected.All.Delayed == 0 {
+		t.Fatalf("Expected delayed ticks only, got %d missed and %d delayed", corrected.All.Missed, corrected.All.Delayed)
+	}
+
+}
+
+func TestArrival(t *testing.T) {
+	for name, arrival := range arrivals {
+		var total float64
+		const samples = 100000
+		for i := 0; i < samples; i++ {
+			total += arrival()
+		}
+		if mean := total / samples; mean < 0.95 || mean > 1.05 {
+			t.Fatalf("Arrival %s: expected mean close to 1, got %v", name, mean)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 500
+	b.Arrival = "poisson"
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan struct{})
+	go func() {
+		must(t, b.start(ctx))
+		close(finished)
+	}()
+
+	<-time.After(time.Millisecond * 500)
+
+	stats := b.Stats()
+	if stats.Segments[0].ActualRate < 300 || stats.Segments[0].ActualRate > 700 {
+		t.Fatalf("Expected actual rate close to 500, got %v", stats.Segments[0].ActualRate)
+	}
+
+	b.Exit()
+	<-finished
+}
+
+func TestClosedLoop(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.ClosedLoop = true
+	b.Workers = 5
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewHang(10))
+
+	finished := make(chan struct{})
+	go func() {
+		must(t, b.start(ctx))
+		close(finished)
+	}()
+
+	<-time.After(time.Millisecond * 200)
+
+	// 5 workers each taking 10ms should start about 100 items in 200ms
+	stats := b.Stats()
+	if stats.All.Summary.Started < 50 || stats.All.Summary.Started > 105 {
+		t.Fatalf("Expected about 100 started, got %d", stats.All.Summary.Started)
+	}
+	if stats.All.Missed != 0 {
+		t.Fatalf("Expected no missed ticks, got %d", stats.All.Missed)
+	}
+	if !regexp.MustCompile(`Desired rate:\s+\(all\)\s+max`).MatchString(stats.String()) {
+		t.Fatal("Unexpected stat string:", stats.String())
+	}
+
+	b.Exit()
+	<-finished
+
+}
+
+func TestClosedLoopData(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.ClosedLoop = true
+	b.Workers = 2
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	b.Headers = []string{"head"}
+	b.SetData(strings.NewReader("a\nb\nc"))
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.All.Summary.Success != 3 {
+		t.Fatalf("Expected 3 successful items, got %d", stats.All.Summary.Success)
+	}
+
+	b.Exit()
+
+}
+
+func TestSearch(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Workers = 20
+	b.Search = &Search{Start: 50, Duration: "400ms", FailFraction: 0.1, Precision: 0.1}
+
+	// the target starts to fail above 150 requests / second
+	b.SetWorker(func() Worker {
+		return &ExampleWorker{
+			SendFunc: func(ctx context.Context, self *ExampleWorker, in map[string]interface{}) (map[string]interface{}, error) {
+				// read the rate of the current segment, because b.Rate is written by the ticker loop.
+				b.metrics.sync.RLock()
+				rate := b.metrics.segments[b.metrics.current].rate
+				b.metrics.sync.RUnlock()
+				if rate > 150 {
+					return nil, errors.New("fail")
+				}
+				return nil, nil
+			},
+		}
+	})
+
+	out := new(ThreadSafeBuffer)
+	b.SetOutput(out)
+
+	// 50 (pass), 100 (pass), 200 (fail), 150 (pass), 175 (fail), 162.5 (fail) then finish
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.SustainableRate != 150 {
+		t.Fatalf("Expected sustainable rate 150, got %v", stats.SustainableRate)
+	}
+	if len(stats.Segments) != 6 {
+		t.Fatalf("Expected 6 segments, got %d", len(stats.Segments))
+	}
+	mustMatch(t, out, 1, `\QHighest sustainable rate is 150 requests / second.\E`)
+	if !regexp.MustCompile(`Sustainable rate:\s+150 requests / second`).MatchString(stats.String()) {
+		t.Fatal("Unexpected stat string:", stats.String())
+	}
+
+	b.Exit()
+
+}
+
+func TestSearchNoSustainableRate(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Workers = 20
+	b.Search = &Search{Start: 200, Duration: "100ms"}
+
+	// the target is down, so every segment fails
+	b.SetWorker(func() Worker {
+		return &ExampleWorker{
+			SendFunc: func(ctx context.Context, self *ExampleWorker, in map[string]interface{}) (map[string]interface{}, error) {
+				return nil, errors.New("fail")
+			},
+		}
+	})
+
+	out := new(ThreadSafeBuffer)
+	b.SetOutput(out)
+
+	// 200, 100, 50, 25, 12.5, 6.25, 3.125, 1.5625 (all fail) then finish
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.SustainableRate != 0 || stats.StopReason != "search finished" {
+		t.Fatalf("Expected no sustainable rate, got %v (%s)", stats.SustainableRate, stats.StopReason)
+	}
+	if len(stats.Segments) != 8 {
+		t.Fatalf("Expected 8 segments, got %d", len(stats.Segments))
+	}
+	mustMatch(t, out, 1, `\QSearch finished. No sustainable rate found.\E`)
+
+	b.Exit()
+
+}
+
+func TestCommands(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.SetTimeout(time.Second)
+
+	input, inputWriter := io.Pipe()
+	b.SetInput(input)
+
+	out := new(ThreadSafeBuffer)
+	b.SetOutput(out)
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	waitFor := func(description string, f func() bool) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for !f() {
+			select {
+			case <-timeout:
+				t.Fatalf("Timeout waiting for %s:\n%s", description, out.String())
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+	command := func(s string) {
+		io.WriteString(inputWriter, s+"\n")
+	}
+	contains := func(s string) func() bool {
+		return func() bool { return strings.Contains(out.String(), s) }
+	}
+
+	command("foo")
+	waitFor("unknown command", contains(`Unknown command "foo". Enter "help" for a list of commands.`))
+
+	command("rate x")
+	waitFor("invalid rate", contains(`Invalid rate "x".`))
+
+	command("workers")
+	waitFor("missing argument", contains(`The workers command needs one argument.`))
+
+	command("help")
+	waitFor("help", contains("Commands:\n"))
+
+	command("timeout 500ms")
+	waitFor("timeout", contains("Timeout is 500ms."))
+	if soft, hard := b.timeouts(); soft != time.Millisecond*500 || hard != time.Millisecond*1500 {
+		t.Fatalf("Unexpected timeouts %s, %s", soft, hard)
+	}
+
+	// several commands in a single write (e.g. pasted) are all executed
+	command("timeout 250ms\ntimeout 300ms")
+	waitFor("pasted commands", contains("Timeout is 300ms."))
+
+	command("pause")
+	waitFor("pause", contains("Paused."))
+	if !b.Stats().Paused {
+		t.Fatal("Expected sending to be paused")
+	}
+
+	command("resume")
+	waitFor("resume", contains("Resumed."))
+	if b.Stats().Paused {
+		t.Fatal("Expected sending to be resumed")
+	}
+
+	command("rate 10")
+	waitFor("rate", func() bool {
+		segments := b.Stats().Segments
+		return len(segments) > 0 && segments[len(segments)-1].DesiredRate == 10
+	})
+
+	command("stop")
+	must(t, <-finished)
+
+	if reason := b.Stats().StopReason; reason != "stopped" {
+		t.Fatalf("Unexpected stop reason %q", reason)
+	}
+
+	b.Exit()
+
+}
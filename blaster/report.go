@@ -0,0 +1,89 @@
+package blaster
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Report is the final report of a run, written to the report file. See Config.ReportFile for more
+// details.
+type Report struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	StopReason string    `json:"stop-reason"`
+	Error      string    `json:"error,omitempty"`
+	Config     *Config   `json:"config,omitempty"`
+	Stats      Stats     `json:"stats"`
+}
+
+// Report returns the final report of the run. The config is only included if the Blaster was
+// configured with Initialise.
+func (b *Blaster) Report(stats Stats) Report {
+	r := Report{
+		Start:      b.startTime,
+		End:        b.endTime,
+		StopReason: stats.StopReason,
+		Config:     b.config,
+		Stats:      stats,
+	}
+	if b.err != nil {
+		r.Error = b.err.Error()
+	}
+	return r
+}
+
+// WriteReport writes the final report of the run to w in the format set by OutputFormat.
+func (b *Blaster) WriteReport(w io.Writer, stats Stats) error {
+	r := b.Report(stats)
+	switch b.OutputFormat {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(r); err != nil {
+			return errors.WithStack(err)
+		}
+	case "", "text":
+		if _, err := fmt.Fprint(w, r.String()); err != nil {
+			return errors.WithStack(err)
+		}
+	default:
+		return errors.Errorf("output-format %s not found", b.OutputFormat)
+	}
+	return nil
+}
+
+// String returns a text representation of the report.
+func (r Report) String() string {
+	s := fmt.Sprintf("Start:  %s\nEnd:    %s\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339))
+	if r.Error != "" {
+		s += fmt.Sprintf("Error:  %s\n", r.Error)
+	}
+	if r.Config != nil {
+		j, _ := json.MarshalIndent(r.Config, "", "\t")
+		s += fmt.Sprintf("\nConfig\n======\n%s\n", j)
+	}
+	return s + "\n" + r.Stats.String()
+}
+
+func (b *Blaster) writeReportFile(stats Stats) error {
+
+	// notest
+
+	if b.reportFile == "" {
+		return nil
+	}
+	f, err := os.Create(b.reportFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := b.WriteReport(f, stats); err != nil {
+		return err
+	}
+	return errors.WithStack(f.Close())
+}
@@ -0,0 +1,60 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	must(t, b.Initialise(ctx, Config{Rate: 1000, Workers: 2, Headers: []string{"head"}, OutputFormat: "json"}))
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+	b.SetData(strings.NewReader("a\nb\nc"))
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	buf := new(bytes.Buffer)
+	must(t, b.WriteReport(buf, stats))
+
+	var r Report
+	must(t, json.Unmarshal(buf.Bytes(), &r))
+
+	if r.StopReason != "end of data" || r.Stats.StopReason != "end of data" {
+		t.Fatalf("Unexpected stop reason: %q", r.StopReason)
+	}
+	if r.Start.IsZero() || r.End.Before(r.Start) {
+		t.Fatalf("Unexpected start and end: %v, %v", r.Start, r.End)
+	}
+	if r.Config == nil || r.Config.Rate != 1000 || r.Config.Headers[0] != "head" {
+		t.Fatalf("Unexpected config: %#v", r.Config)
+	}
+	if r.Stats.All.Summary.Success != 3 || len(r.Stats.Segments) != 1 || r.Stats.Segments[0].Summary.Success != 3 {
+		t.Fatalf("Unexpected stats: %s", buf.String())
+	}
+	if len(r.Stats.All.Status) != 1 || r.Stats.All.Status[0].Status != "[success]" || r.Stats.All.Status[0].Count != 3 {
+		t.Fatalf("Unexpected status: %s", buf.String())
+	}
+	if len(r.Stats.All.Summary.Percentiles) != len(r.Stats.Percentiles) {
+		t.Fatalf("Unexpected percentiles: %s", buf.String())
+	}
+
+	b.OutputFormat = "text"
+	buf = new(bytes.Buffer)
+	must(t, b.WriteReport(buf, stats))
+	for _, expected := range []string{"Start:", "End:", "\"rate\": 1000", "Stop reason:      end of data", "Success:          3"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Fatalf("Expected %q in report:\n%s", expected, buf.String())
+		}
+	}
+
+	b.Exit()
+
+}
@@ -0,0 +1,93 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRetry(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 100
+	b.Retry = &Retry{MaxAttempts: 3, Backoff: "10ms", Statuses: []string{"503"}}
+
+	// "a" fails with a retryable status until the third attempt, "b" fails with a status that
+	// isn't retryable, and "c" succeeds.
+	var m sync.Mutex
+	attempts := map[string]int{}
+	b.SetWorker(func() Worker {
+		return &ExampleWorker{
+			SendFunc: func(ctx context.Context, self *ExampleWorker, in map[string]interface{}) (map[string]interface{}, error) {
+				m.Lock()
+				defer m.Unlock()
+				head := in["head"].(string)
+				attempts[head]++
+				switch {
+				case head == "a" && attempts[head] < 3:
+					return map[string]interface{}{"status": 503}, errors.New("fail")
+				case head == "b":
+					return map[string]interface{}{"status": 400}, errors.New("fail")
+				}
+				return map[string]interface{}{"status": 200}, nil
+			},
+		}
+	})
+	b.SetPayloadTemplate(map[string]interface{}{"head": "{{ .head }}"})
+
+	log := &LoggingWriter{buf: new(bytes.Buffer)}
+	b.SetLog(log)
+	b.LogData = []string{"head"}
+	must(t, b.WriteLogHeaders())
+
+	b.Headers = []string{"head"}
+	b.SetData(strings.NewReader("a\nb\nc"))
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if attempts["a"] != 3 || attempts["b"] != 1 || attempts["c"] != 1 {
+		t.Fatalf("Unexpected attempts: %v", attempts)
+	}
+	if stats.All.Summary.Started != 5 || stats.All.Summary.Retries != 2 || stats.All.Summary.Success != 2 || stats.All.Summary.Fail != 3 {
+		t.Fatalf("Unexpected summary: %#v", stats.All.Summary)
+	}
+	if stats.StopReason != "end of data" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+	if !strings.Contains(stats.String(), "Retries:") {
+		t.Fatal("Unexpected stat string:", stats.String())
+	}
+
+	b.Exit()
+
+	log.mustLen(t, 6)
+	log.must(t, 0, []string{"hash", "result", "attempt", "head"})
+	results := map[string]bool{}
+	for _, record := range log.All()[1:] {
+		results[record[3]+record[2]+record[1]] = true
+	}
+	for _, expected := range []string{"a1false", "a2false", "a3true", "b1false", "c1true"} {
+		if !results[expected] {
+			t.Fatalf("Expected %s in log: %v", expected, log.All())
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	def, err := Retry{Backoff: "100ms", MaxBackoff: "300ms", Jitter: 0.1}.parse()
+	must(t, err)
+	for attempt, expected := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 4: 300} {
+		expected = expected * time.Millisecond
+		d := def.delay(attempt)
+		if d < expected*9/10 || d > expected*11/10 {
+			t.Fatalf("Unexpected delay for attempt %d: %v", attempt, d)
+		}
+	}
+}
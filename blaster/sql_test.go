@@ -0,0 +1,123 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSplitSQL(t *testing.T) {
+	tests := map[string][]string{
+		"sqlite://jobs.db?query=SELECT a FROM b":                                 {"sqlite", "jobs.db", "SELECT a FROM b"},
+		"sqlite://jobs.db?query=SELECT%20a%20FROM%20b%20WHERE%20c%2B1":           {"sqlite", "jobs.db", "SELECT%20a%20FROM%20b%20WHERE%20c%2B1"},
+		"sqlite://file:jobs.db?mode=ro&query=SELECT a FROM b WHERE c='?query=&'": {"sqlite", "file:jobs.db?mode=ro", "SELECT a FROM b WHERE c='?query=&'"},
+		"sqlite://jobs.db": {"sqlite", "jobs.db", ""},
+	}
+	for value, expected := range tests {
+		driver, dsn, query, ok := splitSQL(value)
+		if !ok || !reflect.DeepEqual([]string{driver, dsn, query}, expected) {
+			t.Fatalf("Unexpected split of %s: %q %q %q", value, driver, dsn, query)
+		}
+	}
+	for _, value := range []string{"jobs.db", "https://a/b?query=c", "gs://a/b", "foo://a?query=b", "a\nsqlite://b"} {
+		if _, _, _, ok := splitSQL(value); ok {
+			t.Fatalf("%s should not be a sql data source", value)
+		}
+	}
+}
+
+func createJobs(t *testing.T, dir string) string {
+	t.Helper()
+	name := filepath.Join(dir, "jobs.db")
+	db, err := sql.Open("sqlite", name)
+	must(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE jobs (a TEXT, n INTEGER, c TEXT);
+		INSERT INTO jobs VALUES ('x', 1, NULL), ('y', 2, 'z'), ('w', 3, NULL);`)
+	must(t, err)
+	return name
+}
+
+func TestOpenDataSQL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	name := createJobs(t, dir)
+
+	b := New(ctx, cancel)
+	must(t, b.openData(ctx, "sqlite://"+name+"?query=SELECT+a,+n,+c+FROM+jobs+WHERE+n%3C%3D3+ORDER+BY+n", true))
+	defer b.Exit()
+	if !reflect.DeepEqual(b.Headers, []string{"a", "n", "c"}) {
+		t.Fatalf("Unexpected headers: %v", b.Headers)
+	}
+	var records [][]string
+	for {
+		record, err := b.dataReader.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+	if expected := [][]string{{"x", "1", ""}, {"y", "2", "z"}, {"w", "3", ""}}; !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Unexpected records: %v", records)
+	}
+}
+
+func TestOpenDataSQLError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	name := createJobs(t, dir)
+
+	b := New(ctx, cancel)
+	if err := b.openData(ctx, "sqlite://"+name, true); err == nil || !strings.Contains(err.Error(), "query parameter") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := b.openData(ctx, "sqlite://"+name+"?query=SELECT a FROM missing", true); err == nil || !strings.Contains(err.Error(), "running sqlite data query") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := b.openData(ctx, "sqlite://"+name+"?query=SELECT a FROM jobs WHERE a LIKE '%x'", true); err == nil || !strings.Contains(err.Error(), "decoding sqlite data query") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b.SkipRows = 1
+	if err := b.openData(ctx, "sqlite://"+name+"?query=SELECT a FROM jobs", true); err == nil || !strings.Contains(err.Error(), "can't be used with sqlite data") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestSQLDataResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	dir, err := ioutil.TempDir("", "")
+	must(t, err)
+	defer os.RemoveAll(dir)
+	value := "sqlite://" + createJobs(t, dir) + "?query=SELECT a FROM jobs ORDER BY n"
+
+	b := New(ctx, cancel)
+	b.MaxRequests = 2
+	must(t, b.openData(ctx, value, true))
+	log := new(bytes.Buffer)
+	values, _ := runLoggedTo(t, ctx, b, log)
+	if expected := []string{"x", "y"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	b = New(ctx, cancel)
+	b.Resume = true
+	must(t, b.openData(ctx, value, true))
+	must(t, b.LoadLogs(log))
+	values, stats := runLogged(t, ctx, b)
+	if expected := []string{"w"}; !reflect.DeepEqual(values, expected) || stats.Skipped != 2 {
+		t.Fatalf("Unexpected values: %v, skipped %d", values, stats.Skipped)
+	}
+}
@@ -4,47 +4,68 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"text/tabwriter"
 	"time"
 )
 
 // Stats is a snapshot of the metrics (as is printed during interactive execution).
 type Stats struct {
-	ConcurrencyCurrent int
-	ConcurrencyMaximum int
-	Skipped            int64
-	All                *Segment
-	Segments           []*Segment
+	ClosedLoop          bool       `json:"closed-loop"`
+	Paused              bool       `json:"paused"`
+	ConcurrencyCurrent  int        `json:"concurrency-current"`
+	ConcurrencyMaximum  int        `json:"concurrency-maximum"`
+	Skipped             int64      `json:"skipped"`
+	SustainableRate     float64    `json:"sustainable-rate"`          // highest sustainable rate found in search mode
+	StopReason          string     `json:"stop-reason"`               // reason the run ended, e.g. "end of data" or "duration 30s reached"
+	CircuitBreaker      string     `json:"circuit-breaker"`           // state of the circuit breaker: closed, open or half-open (empty if disabled)
+	CircuitBreakerTrips int64      `json:"circuit-breaker-trips"`     // number of times the circuit breaker has tripped
+	Percentiles         []float64  `json:"percentiles"`               // latency percentiles reported in each Total and Status, e.g. 99.9
+	DataFile            string     `json:"data-file,omitempty"`       // data file currently being read, if the data source is several files
+	DataFileIndex       int        `json:"data-file-index,omitempty"` // position of the current data file, starting at 1
+	DataFiles           int        `json:"data-files,omitempty"`      // number of data files
+	DataLoop            int        `json:"data-loop,omitempty"`       // number of passes over the data, or -1 to loop until stopped
+	DataPasses          int64      `json:"data-passes,omitempty"`     // completed passes over the data
+	All                 *Segment   `json:"all"`
+	Segments            []*Segment `json:"segments"`
 }
 
 // Segment is a rate segment - a new segment is created each time the rate is changed.
 type Segment struct {
-	DesiredRate        float64
-	ActualRate         float64
-	AverageConcurrency float64
-	Duration           time.Duration
-	Summary            *Total
-	Status             []*Status
+	DesiredRate        float64       `json:"desired-rate"`
+	ActualRate         float64       `json:"actual-rate"`
+	AverageConcurrency float64       `json:"average-concurrency"`
+	Duration           time.Duration `json:"duration"`
+	Missed             int64         `json:"missed"`  // ticks that were dropped because no worker was idle
+	Delayed            int64         `json:"delayed"` // ticks that were queued in correct-latency mode because no worker was idle, and sent late
+	Summary            *Total        `json:"summary"`
+	Status             []*Status     `json:"status"`
 }
 
 // Total is the summary of all requests in this segment
 type Total struct {
-	Started     int64
-	Finished    int64
-	Success     int64
-	Fail        int64
-	Mean        time.Duration
-	NinetyFifth time.Duration
+	Started     int64           `json:"started"`
+	Finished    int64           `json:"finished"`
+	Success     int64           `json:"success"`
+	Fail        int64           `json:"fail"`
+	Retries     int64           `json:"retries"` // requests that were retries of failed items (included in Started)
+	Mean        time.Duration   `json:"mean"`
+	NinetyFifth time.Duration   `json:"ninety-fifth"`
+	Percentiles []time.Duration `json:"percentiles"` // latency at each of Stats.Percentiles
+	Max         time.Duration   `json:"max"`
 }
 
 // Status is a summary of all requests that returned a specific status
 type Status struct {
-	Status      string
-	Count       int64
-	Fraction    float64
-	Mean        time.Duration
-	NinetyFifth time.Duration
+	Status      string          `json:"status"`
+	Count       int64           `json:"count"`
+	Fraction    float64         `json:"fraction"`
+	Mean        time.Duration   `json:"mean"`
+	NinetyFifth time.Duration   `json:"ninety-fifth"`
+	Percentiles []time.Duration `json:"percentiles"` // latency at each of Stats.Percentiles
+	Max         time.Duration   `json:"max"`
 }
 
 func (m *metricsDef) stats() Stats {
@@ -78,43 +99,71 @@ func (m *metricsDef) stats() Stats {
 		}
 	}
 
+	s.ClosedLoop = m.blaster.ClosedLoop
+	s.Paused = atomic.LoadInt32(&m.blaster.paused) == 1
+	s.Percentiles = m.blaster.Percentiles
 	s.Skipped = m.skipped.Count()
+	s.DataFile, s.DataFileIndex, s.DataFiles = m.blaster.dataFile()
+	if m.blaster.DataLoop != 0 {
+		s.DataLoop = m.blaster.DataLoop
+		s.DataPasses = m.blaster.dataPasses()
+	}
+	s.SustainableRate = m.sustained
+	s.StopReason = m.reason
+	if m.blaster.breaker != nil {
+		// breaker is set under the metrics lock, which we hold
+		s.CircuitBreaker, s.CircuitBreakerTrips = m.blaster.breaker.status()
+	}
 	s.ConcurrencyCurrent = int(m.busy.Count())
-	s.ConcurrencyMaximum = m.blaster.Workers
+	s.ConcurrencyMaximum = m.blaster.currentWorkers()
 	s.All.ActualRate = float64(m.all.total.start.Count()) / m.all.duration().Seconds()
 	s.All.AverageConcurrency = m.all.busy.Mean()
 	s.All.Duration = m.all.duration()
+	s.All.Missed = m.all.missed.Count()
+	s.All.Delayed = m.all.delayed.Count()
 	s.All.Summary.Started = m.all.total.start.Count()
 	s.All.Summary.Finished = m.all.total.finish.Count()
 	s.All.Summary.Success = m.all.total.success.Count()
 	s.All.Summary.Fail = m.all.total.fail.Count()
-	s.All.Summary.Mean = time.Duration(m.all.total.finish.Mean()/1000000.0) * time.Millisecond
-	s.All.Summary.NinetyFifth = time.Duration(m.all.total.finish.Percentile(0.95)/1000000.0) * time.Millisecond
+	s.All.Summary.Retries = m.all.total.retry.Count()
+	s.All.Summary.Mean = m.all.total.finish.Mean()
+	s.All.Summary.NinetyFifth = m.all.total.finish.Percentile(95)
+	s.All.Summary.Percentiles = m.all.total.finish.Percentiles(s.Percentiles)
+	s.All.Summary.Max = m.all.total.finish.Max()
 
 	for i, seg := range s.Segments {
 		seg.DesiredRate = m.segments[i].rate
 		seg.ActualRate = float64(m.segments[i].total.start.Count()) / m.segments[i].duration().Seconds()
 		seg.AverageConcurrency = m.segments[i].busy.Mean()
 		seg.Duration = m.segments[i].duration()
+		seg.Missed = m.segments[i].missed.Count()
+		seg.Delayed = m.segments[i].delayed.Count()
 		seg.Summary.Started = m.segments[i].total.start.Count()
 		seg.Summary.Finished = m.segments[i].total.finish.Count()
 		seg.Summary.Success = m.segments[i].total.success.Count()
 		seg.Summary.Fail = m.segments[i].total.fail.Count()
-		seg.Summary.Mean = time.Duration(m.segments[i].total.finish.Mean()/1000000.0) * time.Millisecond
-		seg.Summary.NinetyFifth = time.Duration(m.segments[i].total.finish.Percentile(0.95)/1000000.0) * time.Millisecond
+		seg.Summary.Retries = m.segments[i].total.retry.Count()
+		seg.Summary.Mean = m.segments[i].total.finish.Mean()
+		seg.Summary.NinetyFifth = m.segments[i].total.finish.Percentile(95)
+		seg.Summary.Percentiles = m.segments[i].total.finish.Percentiles(s.Percentiles)
+		seg.Summary.Max = m.segments[i].total.finish.Max()
 	}
 
 	for statusIndex, status := range statuses {
 		s.All.Status[statusIndex].Count = m.all.status[status].finish.Count()
 		s.All.Status[statusIndex].Fraction = float64(m.all.status[status].finish.Count()) / float64(m.all.total.finish.Count())
-		s.All.Status[statusIndex].Mean = time.Duration(m.all.status[status].finish.Mean()/1000000.0) * time.Millisecond
-		s.All.Status[statusIndex].NinetyFifth = time.Duration(m.all.status[status].finish.Percentile(0.95)/1000000.0) * time.Millisecond
+		s.All.Status[statusIndex].Mean = m.all.status[status].finish.Mean()
+		s.All.Status[statusIndex].NinetyFifth = m.all.status[status].finish.Percentile(95)
+		s.All.Status[statusIndex].Percentiles = m.all.status[status].finish.Percentiles(s.Percentiles)
+		s.All.Status[statusIndex].Max = m.all.status[status].finish.Max()
 		for segmentIndex, seg := range s.Segments {
 			if m.segments[segmentIndex].status[status] != nil {
 				seg.Status[statusIndex].Count = m.segments[segmentIndex].status[status].finish.Count()
 				seg.Status[statusIndex].Fraction = float64(m.segments[segmentIndex].status[status].finish.Count()) / float64(m.segments[segmentIndex].total.finish.Count())
-				seg.Status[statusIndex].Mean = time.Duration(m.segments[segmentIndex].status[status].finish.Mean()/1000000.0) * time.Millisecond
-				seg.Status[statusIndex].NinetyFifth = time.Duration(m.segments[segmentIndex].status[status].finish.Percentile(0.95)/1000000.0) * time.Millisecond
+				seg.Status[statusIndex].Mean = m.segments[segmentIndex].status[status].finish.Mean()
+				seg.Status[statusIndex].NinetyFifth = m.segments[segmentIndex].status[status].finish.Percentile(95)
+				seg.Status[statusIndex].Percentiles = m.segments[segmentIndex].status[status].finish.Percentiles(s.Percentiles)
+				seg.Status[statusIndex].Max = m.segments[segmentIndex].status[status].finish.Max()
 			}
 		}
 	}
@@ -141,12 +190,44 @@ func (s Stats) String() string {
 		fmt.Fprintf(w, "Skipped:\t%d from previous runs\n", s.Skipped)
 	}
 
+	if s.StopReason != "" {
+		fmt.Fprintf(w, "Stop reason:\t%s\n", s.StopReason)
+	}
+
+	if s.SustainableRate > 0 {
+		fmt.Fprintf(w, "Sustainable rate:\t%.0f requests / second\n", s.SustainableRate)
+	}
+
+	if s.Paused {
+		fmt.Fprint(w, "Paused:\tsending is paused\n")
+	}
+
+	if s.DataFile != "" {
+		fmt.Fprintf(w, "Data file:\t%s (%d of %d)\n", s.DataFile, s.DataFileIndex, s.DataFiles)
+	}
+
+	switch {
+	case s.DataLoop == -1:
+		fmt.Fprintf(w, "Data passes:\t%d completed (looping until stopped)\n", s.DataPasses)
+	case s.DataLoop > 0:
+		fmt.Fprintf(w, "Data passes:\t%d of %d completed\n", s.DataPasses, s.DataLoop)
+	}
+
+	if s.CircuitBreaker != "" {
+		fmt.Fprintf(w, "Circuit breaker:\t%s (tripped %d times)\n", s.CircuitBreaker, s.CircuitBreakerTrips)
+	}
+
 	fmt.Fprintf(w, "Concurrency:\t%d / %d workers in use\n", s.ConcurrencyCurrent, s.ConcurrencyMaximum)
 	fmt.Fprintf(w, "%s\n", tabs)
 
 	fmt.Fprint(w, "Desired rate:\t(all)\t")
 	for _, i := range segments {
-		fmt.Fprintf(w, "%.0f\t", s.Segments[i].DesiredRate)
+		if s.ClosedLoop {
+			// in closed-loop mode there's no rate limit
+			fmt.Fprint(w, "max\t")
+		} else {
+			fmt.Fprintf(w, "%.0f\t", s.Segments[i].DesiredRate)
+		}
 	}
 	fmt.Fprintf(w, "%s\n", tabs)
 
@@ -171,6 +252,24 @@ func (s Stats) String() string {
 	}
 	fmt.Fprintf(w, "%s\n", tabs)
 
+	if s.All.Missed > 0 {
+		fmt.Fprint(w, "Missed ticks:\t")
+		fmt.Fprintf(w, "%d\t", s.All.Missed)
+		for _, i := range segments {
+			fmt.Fprintf(w, "%d\t", s.Segments[i].Missed)
+		}
+		fmt.Fprintf(w, "%s\n", tabs)
+	}
+
+	if s.All.Delayed > 0 {
+		fmt.Fprint(w, "Delayed ticks:\t")
+		fmt.Fprintf(w, "%d\t", s.All.Delayed)
+		for _, i := range segments {
+			fmt.Fprintf(w, "%d\t", s.Segments[i].Delayed)
+		}
+		fmt.Fprintf(w, "%s\n", tabs)
+	}
+
 	fmt.Fprintf(w, "%s\n", tabs)
 	fmt.Fprintf(w, "Total%s\n", tabs)
 	fmt.Fprintf(w, "-----%s\n", tabs)
@@ -195,16 +294,32 @@ func (s Stats) String() string {
 		fmt.Fprintf(w, "%d\t", s.Segments[i].Summary.Fail)
 	}
 	fmt.Fprint(w, "\n")
+	if s.All.Summary.Retries > 0 {
+		fmt.Fprintf(w, "Retries:\t%d\t", s.All.Summary.Retries)
+		for _, i := range segments {
+			fmt.Fprintf(w, "%d\t", s.Segments[i].Summary.Retries)
+		}
+		fmt.Fprint(w, "\n")
+	}
 	fmt.Fprintf(w, "Mean:\t%.1f ms\t", s.All.Summary.Mean.Seconds()*1000)
 	for _, i := range segments {
 		fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Summary.Mean.Seconds()*1000)
 	}
 	fmt.Fprintf(w, "%s\n", tabs)
-	fmt.Fprintf(w, "95th:\t%.1f ms\t", s.All.Summary.NinetyFifth.Seconds()*1000)
-	for _, i := range segments {
-		fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Summary.NinetyFifth.Seconds()*1000)
+	for index, label := range s.percentileLabels() {
+		fmt.Fprintf(w, "%s:\t%.1f ms\t", label, s.All.Summary.percentile(s, index).Seconds()*1000)
+		for _, i := range segments {
+			fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Summary.percentile(s, index).Seconds()*1000)
+		}
+		fmt.Fprintf(w, "%s\n", tabs)
+	}
+	if s.All.Summary.Max > 0 {
+		fmt.Fprintf(w, "Max:\t%.1f ms\t", s.All.Summary.Max.Seconds()*1000)
+		for _, i := range segments {
+			fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Summary.Max.Seconds()*1000)
+		}
+		fmt.Fprintf(w, "%s\n", tabs)
 	}
-	fmt.Fprintf(w, "%s\n", tabs)
 
 	for status := range s.All.Status {
 
@@ -231,20 +346,68 @@ func (s Stats) String() string {
 			}
 		}
 		fmt.Fprintf(w, "%s\n", tabs)
-		fmt.Fprintf(w, "95th:\t%.1f ms\t", s.All.Status[status].NinetyFifth.Seconds()*1000)
-		for _, i := range segments {
-			if s.Segments[i].Status[status].Count == 0 {
-				fmt.Fprint(w, "-\t")
-			} else {
-				fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Status[status].NinetyFifth.Seconds()*1000)
+		for index, label := range s.percentileLabels() {
+			fmt.Fprintf(w, "%s:\t%.1f ms\t", label, s.All.Status[status].percentile(s, index).Seconds()*1000)
+			for _, i := range segments {
+				if s.Segments[i].Status[status].Count == 0 {
+					fmt.Fprint(w, "-\t")
+				} else {
+					fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Status[status].percentile(s, index).Seconds()*1000)
+				}
 			}
+			fmt.Fprintf(w, "%s\n", tabs)
+		}
+		if s.All.Status[status].Max > 0 {
+			fmt.Fprintf(w, "Max:\t%.1f ms\t", s.All.Status[status].Max.Seconds()*1000)
+			for _, i := range segments {
+				if s.Segments[i].Status[status].Count == 0 {
+					fmt.Fprint(w, "-\t")
+				} else {
+					fmt.Fprintf(w, "%.1f ms\t", s.Segments[i].Status[status].Max.Seconds()*1000)
+				}
+			}
+			fmt.Fprintf(w, "%s\n", tabs)
 		}
-		fmt.Fprintf(w, "%s\n", tabs)
 	}
 	w.Flush()
 	return buf.String()
 }
 
+// percentileLabels returns the row labels for the percentiles, e.g. "99.9th". If no percentiles are
+// configured, only the 95th is printed.
+func (s Stats) percentileLabels() []string {
+	if len(s.Percentiles) == 0 {
+		return []string{"95th"}
+	}
+	var labels []string
+	for _, p := range s.Percentiles {
+		labels = append(labels, percentileLabel(p))
+	}
+	return labels
+}
+
+func percentileLabel(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "th"
+}
+
+func (t *Total) percentile(s Stats, index int) time.Duration {
+	return percentile(s, index, t.NinetyFifth, t.Percentiles)
+}
+
+func (t *Status) percentile(s Stats, index int) time.Duration {
+	return percentile(s, index, t.NinetyFifth, t.Percentiles)
+}
+
+func percentile(s Stats, index int, ninetyFifth time.Duration, percentiles []time.Duration) time.Duration {
+	if len(s.Percentiles) == 0 {
+		return ninetyFifth
+	}
+	if index >= len(percentiles) {
+		return 0
+	}
+	return percentiles[index]
+}
+
 func fmtDuration(d time.Duration) string {
 	sec := int(d.Seconds())
 	min := sec / 60
@@ -1,6 +1,7 @@
 package blaster
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -138,3 +139,84 @@ Mean:             20.0 ms   -         38.0 ms
 		t.Fatal("Unexpected stat string:", s.String())
 	}
 }
+
+func TestStats_StringMissed(t *testing.T) {
+	s := Stats{
+		All: &Segment{
+			Missed:  3,
+			Summary: &Total{},
+		},
+		Segments: []*Segment{
+			{
+				Missed:  1,
+				Summary: &Total{},
+			},
+			{
+				Missed:  2,
+				Summary: &Total{},
+			},
+		},
+	}
+	if !strings.Contains(s.String(), "Missed ticks:     3       2       1") {
+		t.Fatal("Unexpected stat string:", s.String())
+	}
+	if strings.Contains(s.String(), "Delayed ticks:") {
+		t.Fatal("Unexpected stat string:", s.String())
+	}
+	s.All.Delayed = 5
+	s.Segments[1].Delayed = 5
+	if !strings.Contains(s.String(), "Delayed ticks:    5       5       0") {
+		t.Fatal("Unexpected stat string:", s.String())
+	}
+}
+
+func TestStats_StringPercentiles(t *testing.T) {
+	s := Stats{
+		Percentiles: []float64{50, 99.9},
+		All: &Segment{
+			Summary: &Total{
+				Percentiles: []time.Duration{time.Millisecond * 1, time.Millisecond * 2},
+				Max:         time.Millisecond * 3,
+			},
+			Status: []*Status{
+				{
+					Status:      "a",
+					Count:       1,
+					Percentiles: []time.Duration{time.Millisecond * 4, time.Millisecond * 5},
+					Max:         time.Millisecond * 6,
+				},
+			},
+		},
+		Segments: []*Segment{
+			{
+				Summary: &Total{
+					Percentiles: []time.Duration{time.Millisecond * 7, time.Millisecond * 8},
+					Max:         time.Millisecond * 9,
+				},
+				Status: []*Status{
+					{
+						Status:      "a",
+						Count:       1,
+						Percentiles: []time.Duration{time.Millisecond * 10, time.Millisecond * 11},
+						Max:         time.Millisecond * 12,
+					},
+				},
+			},
+		},
+	}
+	for _, expected := range []string{
+		"50th:             1.0 ms  7.0 ms",
+		"99.9th:           2.0 ms  8.0 ms",
+		"Max:              3.0 ms  9.0 ms",
+		"50th:             4.0 ms  10.0 ms",
+		"99.9th:           5.0 ms  11.0 ms",
+		"Max:              6.0 ms  12.0 ms",
+	} {
+		if !strings.Contains(s.String(), expected) {
+			t.Fatalf("Expected %q in:\n%s", expected, s.String())
+		}
+	}
+	if strings.Contains(s.String(), "95th") {
+		t.Fatalf("Unexpected 95th in:\n%s", s.String())
+	}
+}
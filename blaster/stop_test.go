@@ -0,0 +1,121 @@
+package blaster
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 100
+	b.Duration = time.Millisecond * 200
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.StopReason != "duration 200ms reached" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+	if stats.All.Summary.Started < 10 || stats.All.Summary.Started > 25 {
+		t.Fatalf("Expected about 20 started, got %d", stats.All.Summary.Started)
+	}
+	if !regexp.MustCompile(`Stop reason:\s+duration 200ms reached`).MatchString(stats.String()) {
+		t.Fatal("Unexpected stat string:", stats.String())
+	}
+
+	b.Exit()
+
+}
+
+func TestMaxRequests(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 1000
+	b.MaxRequests = 25
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.StopReason != "max-requests 25 reached" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+	if stats.All.Summary.Finished != 25 {
+		t.Fatalf("Expected 25 finished, got %d", stats.All.Summary.Finished)
+	}
+
+	b.Exit()
+
+}
+
+func TestMaxFailures(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 1000
+	b.MaxFailures = 5
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewFail)
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	if stats.StopReason != "max-failures 5 reached" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+	if stats.All.Summary.Fail < 5 || stats.All.Summary.Fail > 15 {
+		t.Fatalf("Expected about 5 failures, got %d", stats.All.Summary.Fail)
+	}
+
+	b.Exit()
+
+}
+
+func TestMaxFailFraction(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.itemFinishedChannel = make(chan struct{})
+	b.MaxFailFraction = 0.2
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewFail)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	// the fraction isn't checked until enough requests have finished
+	for i := 0; i < minFailFractionSample-1; i++ {
+		b.mainChannel <- tickDef{}
+		<-b.itemFinishedChannel
+	}
+	if stats := b.Stats(); stats.StopReason != "" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+
+	b.mainChannel <- tickDef{}
+	<-b.itemFinishedChannel
+
+	must(t, <-finished)
+
+	if stats := b.Stats(); stats.StopReason != "max-fail-fraction 0.2 exceeded" {
+		t.Fatalf("Unexpected stop reason: %q", stats.StopReason)
+	}
+
+	b.Exit()
+
+}
@@ -0,0 +1,230 @@
+package blaster
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeseriesRow is a row in the time-series output. It holds the metrics of a single interval. See
+// Config.TimeseriesFile for more details.
+type TimeseriesRow struct {
+	Time        time.Time                `json:"time"`     // end of the interval
+	Interval    time.Duration            `json:"interval"` // length of the interval (the last interval may be short)
+	Segment     int                      `json:"segment"`
+	DesiredRate float64                  `json:"desired-rate"`
+	Started     int64                    `json:"started"`
+	Finished    int64                    `json:"finished"`
+	Success     int64                    `json:"success"`
+	Fail        int64                    `json:"fail"`
+	Busy        int64                    `json:"busy"`
+	Mean        time.Duration            `json:"mean"`
+	Percentiles map[string]time.Duration `json:"percentiles,omitempty"` // keyed by percentile, e.g. "p99.9"
+	Max         time.Duration            `json:"max"`
+}
+
+// metricsInterval accumulates the metrics of the current interval. It's protected by the metricsDef
+// mutex.
+type metricsInterval struct {
+	start   int64
+	success int64
+	fail    int64
+	finish  *histogram
+	begin   time.Time
+}
+
+func newMetricsInterval() *metricsInterval {
+	return &metricsInterval{
+		finish: newHistogram(),
+		begin:  time.Now(),
+	}
+}
+
+func (i *metricsInterval) logFinish(elapsed time.Duration, success bool) {
+	i.finish.Update(elapsed)
+	if success {
+		i.success++
+	} else {
+		i.fail++
+	}
+}
+
+// timeseries returns the metrics of the current interval and starts a new interval.
+func (m *metricsDef) timeseries(percentiles []float64) TimeseriesRow {
+	m.sync.Lock()
+	defer m.sync.Unlock()
+
+	i := m.interval
+	m.interval = newMetricsInterval()
+
+	now := m.interval.begin
+	row := TimeseriesRow{
+		Time:     now,
+		Interval: now.Sub(i.begin),
+		Segment:  m.current,
+		Started:  i.start,
+		Finished: i.finish.Count(),
+		Success:  i.success,
+		Fail:     i.fail,
+		Busy:     m.busy.Count(),
+		Mean:     i.finish.Mean(),
+		Max:      i.finish.Max(),
+	}
+	if len(m.segments) > 0 {
+		row.DesiredRate = m.segments[m.current].rate
+	}
+	if len(percentiles) > 0 {
+		row.Percentiles = map[string]time.Duration{}
+		for n, d := range i.finish.Percentiles(percentiles) {
+			row.Percentiles[percentileKey(percentiles[n])] = d
+		}
+	}
+	return row
+}
+
+func percentileKey(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// SetTimeseries sets the time-series output. If the provided writer also satisfies io.Closer, it
+// will be closed on exit.
+func (b *Blaster) SetTimeseries(w io.Writer) {
+	b.timeseriesWriter = w
+	if c, ok := w.(io.Closer); ok {
+		b.timeseriesCloser = c
+	} else {
+		b.timeseriesCloser = nil
+	}
+}
+
+func (b *Blaster) openTimeseries(filename string) error {
+
+	// notest
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json", ".jsonl":
+		b.TimeseriesFormat = "json"
+	default:
+		b.TimeseriesFormat = "csv"
+	}
+	b.SetTimeseries(f)
+	return nil
+}
+
+// TimeseriesHeaders returns the csv headers of the time-series output.
+func (b *Blaster) TimeseriesHeaders() []string {
+	headers := []string{"time", "interval", "segment", "desired-rate", "started", "finished", "success", "fail", "busy", "mean"}
+	for _, p := range b.Percentiles {
+		headers = append(headers, percentileKey(p))
+	}
+	return append(headers, "max")
+}
+
+// toCsv returns the csv record of the row. Durations are in milliseconds.
+func (r TimeseriesRow) toCsv(percentiles []float64) []string {
+	ms := func(d time.Duration) string {
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+	}
+	record := []string{
+		r.Time.Format(time.RFC3339Nano),
+		ms(r.Interval),
+		fmt.Sprint(r.Segment),
+		formatFloat(r.DesiredRate),
+		fmt.Sprint(r.Started),
+		fmt.Sprint(r.Finished),
+		fmt.Sprint(r.Success),
+		fmt.Sprint(r.Fail),
+		fmt.Sprint(r.Busy),
+		ms(r.Mean),
+	}
+	for _, p := range percentiles {
+		record = append(record, ms(r.Percentiles[percentileKey(p)]))
+	}
+	return append(record, ms(r.Max))
+}
+
+func (b *Blaster) startTimeseriesLoop(ctx context.Context) {
+
+	if b.timeseriesWriter == nil && b.htmlReport == "" {
+		return
+	}
+
+	var write func(row TimeseriesRow) error
+	switch {
+	case b.timeseriesWriter == nil:
+		write = func(row TimeseriesRow) error { return nil }
+	case b.TimeseriesFormat == "json":
+		enc := json.NewEncoder(b.timeseriesWriter)
+		write = func(row TimeseriesRow) error {
+			return enc.Encode(row)
+		}
+	default:
+		w := csv.NewWriter(b.timeseriesWriter)
+		if err := w.Write(b.TimeseriesHeaders()); err != nil {
+			// notest
+			b.error(errors.WithStack(err))
+			return
+		}
+		write = func(row TimeseriesRow) error {
+			if err := w.Write(row.toCsv(b.Percentiles)); err != nil {
+				// notest
+				return err
+			}
+			w.Flush()
+			return w.Error()
+		}
+	}
+
+	// record writes the row, and keeps it for the html report.
+	record := func() error {
+		row := b.metrics.timeseries(b.Percentiles)
+		if b.htmlReport != "" {
+			b.timeseriesRows = append(b.timeseriesRows, row)
+		}
+		return write(row)
+	}
+
+	// discard anything recorded before the run started.
+	b.metrics.timeseries(nil)
+
+	b.mainWait.Add(1)
+	ticker := time.NewTicker(b.TimeseriesInterval)
+
+	go func() {
+		defer b.mainWait.Done()
+		defer b.println("Exiting timeseries loop")
+		defer ticker.Stop()
+		for {
+			select {
+			// don't react to ctx.Done() here so the requests that finish while the workers drain are
+			// recorded.
+			case <-b.workersFinishedChannel:
+				// write the final (short) interval
+				if err := record(); err != nil {
+					// notest
+					b.error(errors.WithStack(err))
+				}
+				return
+			case <-ticker.C:
+				if err := record(); err != nil {
+					// notest
+					b.error(errors.WithStack(err))
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,108 @@
+package blaster
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeseriesCsv(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.itemFinishedChannel = make(chan struct{})
+	b.Percentiles = []float64{50, 99.9}
+	b.TimeseriesInterval = time.Hour // only the final interval is written
+
+	buf := new(bytes.Buffer)
+	b.SetTimeseries(buf)
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	for i := 0; i < 3; i++ {
+		b.mainChannel <- tickDef{}
+		<-b.itemFinishedChannel
+	}
+
+	close(b.dataFinishedChannel)
+	must(t, <-finished)
+
+	records, err := csv.NewReader(buf).ReadAll()
+	must(t, err)
+
+	expected := "time,interval,segment,desired-rate,started,finished,success,fail,busy,mean,p50,p99.9,max"
+	if len(records) != 2 || strings.Join(records[0], ",") != expected {
+		t.Fatalf("Unexpected timeseries:\n%v", records)
+	}
+	if strings.Join(records[1][2:9], ",") != "0,0,3,3,3,0,0" {
+		t.Fatalf("Unexpected row: %v", records[1])
+	}
+	if _, err := time.Parse(time.RFC3339Nano, records[1][0]); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Exit()
+
+}
+
+func TestTimeseriesJson(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 200
+	b.Workers = 2
+	b.Headers = []string{"head"}
+	b.TimeseriesInterval = time.Millisecond * 20
+	b.TimeseriesFormat = "json"
+
+	buf := new(bytes.Buffer)
+	b.SetTimeseries(buf)
+
+	workerLog := new(LoggingWorker)
+	b.SetWorker(workerLog.NewSuccess)
+	b.SetData(strings.NewReader(strings.Repeat("a\n", 20)))
+
+	stats, err := b.Start(ctx)
+	must(t, err)
+
+	var rows []TimeseriesRow
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var row TimeseriesRow
+		must(t, dec.Decode(&row))
+		rows = append(rows, row)
+	}
+
+	if len(rows) < 2 {
+		t.Fatalf("Expected several rows, got %d", len(rows))
+	}
+
+	var started, success int64
+	for i, row := range rows {
+		started += row.Started
+		success += row.Success
+		if row.DesiredRate != 200 || row.Interval <= 0 || len(row.Percentiles) != len(b.Percentiles) {
+			t.Fatalf("Unexpected row: %#v", row)
+		}
+		if i > 0 && row.Time.Before(rows[i-1].Time) {
+			t.Fatalf("Rows out of order: %#v", rows)
+		}
+	}
+	if started != 20 || success != 20 || stats.All.Summary.Success != 20 {
+		t.Fatalf("Unexpected totals: started %d, success %d", started, success)
+	}
+
+	b.Exit()
+
+}
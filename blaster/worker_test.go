@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestWorkerVariants(t *testing.T) {
@@ -52,7 +55,7 @@ func TestWorkerVariants(t *testing.T) {
 
 	// synthetically call the main channel, which is what the ticker would do
 	for i := 0; i < 1000; i++ {
-		b.mainChannel <- 0
+		b.mainChannel <- tickDef{}
 		<-b.itemFinishedChannel
 	}
 
@@ -119,14 +122,14 @@ func TestDataLog(t *testing.T) {
 	}()
 
 	// synthetically call the main channel, which is what the ticker would do
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 	<-b.itemFinishedChannel
 
 	// another tick and the data will reach EOF, and gracefully exit
-	b.mainChannel <- 0
+	b.mainChannel <- tickDef{}
 
 	// wait for the start method to finish
 	must(t, <-finished)
@@ -145,3 +148,76 @@ func TestStringify(t *testing.T) {
 		t.Fatal("Unexpected:", s)
 	}
 }
+
+func TestChangeWorkers(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(ctx, cancel)
+	b.Rate = 0 // set rate to 0 so we can inject items synthetically
+	b.Workers = 2
+
+	input, inputWriter := io.Pipe()
+	b.SetInput(input)
+
+	var started, stopped int64
+	b.SetWorker(func() Worker {
+		return &ExampleWorker{
+			StartFunc: func(ctx context.Context, self *ExampleWorker, payload map[string]interface{}) error {
+				atomic.AddInt64(&started, 1)
+				return nil
+			},
+			StopFunc: func(ctx context.Context, self *ExampleWorker, payload map[string]interface{}) error {
+				atomic.AddInt64(&stopped, 1)
+				return nil
+			},
+		}
+	})
+
+	finished := make(chan error, 1)
+	go func() {
+		finished <- b.start(ctx)
+	}()
+
+	waitFor := func(description string, f func() bool) {
+		timeout := time.After(time.Second)
+		for !f() {
+			select {
+			case <-timeout:
+				t.Fatalf("Timeout waiting for %s", description)
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+
+	b.ChangeWorkers(5)
+	waitFor("5 workers", func() bool { return atomic.LoadInt64(&started) == 5 && b.Stats().ConcurrencyMaximum == 5 })
+
+	b.ChangeWorkers(1)
+	waitFor("4 workers to stop", func() bool { return atomic.LoadInt64(&stopped) == 4 && b.Stats().ConcurrencyMaximum == 1 })
+
+	io.WriteString(inputWriter, "workers 3\n")
+	waitFor("3 workers", func() bool { return atomic.LoadInt64(&started) == 7 && b.Stats().ConcurrencyMaximum == 3 })
+
+	close(b.dataFinishedChannel)
+	must(t, <-finished)
+
+	if atomic.LoadInt64(&stopped) != 7 {
+		t.Fatalf("Expected all 7 workers to be stopped, got %d", atomic.LoadInt64(&stopped))
+	}
+
+	// changes after the run has finished must not block
+	done := make(chan struct{})
+	go func() {
+		b.ChangeWorkers(2)
+		b.ChangeWorkers(3)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ChangeWorkers blocked after the run finished")
+	}
+
+	b.Exit()
+
+}
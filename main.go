@@ -16,6 +16,9 @@ import (
 	"github.com/dave/blast/dummyworker"
 	"github.com/dave/blast/gcsworker"
 	"github.com/dave/blast/httpworker"
+
+	// register the sqlite driver for sql data sources
+	_ "modernc.org/sqlite"
 )
 
 // Set debug to true to dump full stack info on every error.